@@ -0,0 +1,4469 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"halite/hlt"
+	"halite/replay"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const unowned = 0
+const maxCost = 10000
+const maxStrength = 255
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func opposite(direction hlt.Direction) hlt.Direction {
+	switch direction {
+	case hlt.NORTH:
+		return hlt.SOUTH
+	case hlt.EAST:
+		return hlt.WEST
+	case hlt.SOUTH:
+		return hlt.NORTH
+	case hlt.WEST:
+		return hlt.EAST
+	default:
+		return hlt.STILL
+	}
+}
+
+func LocationString(location hlt.Location) string {
+	return fmt.Sprintf("(x:%d, y:%d)", location.X, location.Y)
+}
+
+func DirectionString(direction hlt.Direction) string {
+	switch direction {
+	case hlt.NORTH:
+		return "NORTH"
+	case hlt.EAST:
+		return "EAST"
+	case hlt.SOUTH:
+		return "SOUTH"
+	case hlt.WEST:
+		return "WEST"
+	default:
+		return "STILL"
+	}
+}
+
+func DirectionArrowString(direction hlt.Direction) string {
+	switch direction {
+	case hlt.NORTH:
+		return "^"
+	case hlt.EAST:
+		return ">"
+	case hlt.SOUTH:
+		return "v"
+	case hlt.WEST:
+		return "<"
+	default:
+		return "x"
+	}
+}
+
+func ScoreString(os OwnerScore) string {
+	return fmt.Sprintf("Score(p:%d, s:%d, t:%d)[%.3f]", os.Production, os.Strength, os.Territory, os.SingleScore(nil))
+}
+
+/*
+███████ ████████  █████   ██████ ██   ██
+██         ██    ██   ██ ██      ██  ██
+███████    ██    ███████ ██      █████
+     ██    ██    ██   ██ ██      ██  ██
+███████    ██    ██   ██  ██████ ██   ██
+*/
+
+type stackable struct {
+	Content  *Cell
+	Previous *stackable
+	Priority int
+}
+
+// Stack is a FILO collection
+type Stack struct {
+	_top    *stackable
+	_length int
+}
+
+// NewStack is a constructor
+func NewStack() *Stack {
+	return &Stack{
+		_top:    nil,
+		_length: 0,
+	}
+}
+
+// Contains returns true if the stack contain the cell.
+func (s *Stack) Contains(cell *Cell) bool {
+	if s.isEmpty() {
+		return false
+	}
+	check := s._top
+	for check.Content != cell && check.Previous != nil {
+		check = check.Previous
+	}
+	return check.Content == cell
+}
+
+// Peek at top item
+func (s *Stack) Peek() (*Cell, error) {
+	if s.isEmpty() {
+		return nil, errors.New("Empty Stack")
+	}
+	return s._top.Content, nil
+}
+
+// Push new item into the Stack
+func (s *Stack) Push(c *Cell) {
+	newTop := &stackable{Content: c, Previous: s._top, Priority: maxCost}
+	s._top = newTop
+	s._length++
+}
+
+// Pop the top item off of the stack
+func (s *Stack) Pop() (*Cell, error) {
+	if s.isEmpty() {
+		return nil, errors.New("Empty Stack")
+	}
+	pop := s._top
+	cell := pop.Content
+	s._top = pop.Previous
+	pop.Previous = nil
+	s._length--
+	return cell, nil
+}
+
+// Push into stack to a depth determined by cell's priority
+func (s *Stack) PushPriority(c *Cell, priority int) {
+	if s._top == nil || priority < s._top.Priority {
+		newTop := &stackable{Content: c, Previous: s._top, Priority: priority}
+		s._top = newTop
+		s._length++
+		return
+	}
+	prev := s._top.Previous
+	current := s._top
+	for prev != nil {
+		// insert cell between current and previous
+		if priority < prev.Priority {
+			current.Previous = &stackable{Content: c, Previous: prev, Priority: priority}
+			s._length++
+			return
+		}
+		current = prev
+		prev = prev.Previous
+	}
+	// at end
+	current.Previous = &stackable{Content: c, Previous: nil, Priority: priority}
+	s._length++
+}
+
+func (s *Stack) isEmpty() bool {
+	return s._length == 0
+}
+
+func (s *Stack) isNotEmpty() bool {
+	return !s.isEmpty()
+}
+
+// PriorityQueue hands back the cells pushed into it lowest priority first, in the
+// order they were pushed when priorities are equal
+type PriorityQueue interface {
+	PushPriority(c *Cell, priority int)
+	Pop() (*Cell, error)
+	isNotEmpty() bool
+}
+
+/*
+██   ██ ███████  █████  ██████
+██   ██ ██      ██   ██ ██   ██
+███████ █████   ███████ ██████
+██   ██ ██      ██   ██ ██
+██   ██ ███████ ██   ██ ██
+*/
+
+type heapItem struct {
+	// map location of the cell, see Heap.key
+	Key      int
+	Priority int
+	// order pushed, breaks ties between equal priorities
+	Sequence int
+}
+
+// Heap is an indexed binary min-heap of cells. A cell is held at most once,
+// pushing it again with a lower priority moves it up (decrease-key).
+type Heap struct {
+	_items []heapItem
+	// position of each map location in _items, -1 when not held
+	_positions []int
+	// cell last pushed at each map location
+	_cells    []*Cell
+	_width    int
+	_sequence int
+}
+
+// NewHeap is a constructor for a Heap of cells on a width x height map
+func NewHeap(width int, height int) *Heap {
+	positions := make([]int, width*height)
+	for i := range positions {
+		positions[i] = -1
+	}
+	return &Heap{
+		_items:     make([]heapItem, 0, width+height),
+		_positions: positions,
+		_cells:     make([]*Cell, width*height),
+		_width:     width,
+	}
+}
+
+func (h *Heap) key(c *Cell) int {
+	return c.Y*h._width + c.X
+}
+
+// Contains returns true if the heap holds the cell
+func (h *Heap) Contains(c *Cell) bool {
+	return h._positions[h.key(c)] >= 0
+}
+
+// Peek at the lowest priority item
+func (h *Heap) Peek() (*Cell, error) {
+	if h.isEmpty() {
+		return nil, errors.New("Empty Heap")
+	}
+	return h._cells[h._items[0].Key], nil
+}
+
+// PushPriority adds the cell, or lowers its priority if it is already held
+func (h *Heap) PushPriority(c *Cell, priority int) {
+	h._sequence++
+	key := h.key(c)
+	item := heapItem{Key: key, Priority: priority, Sequence: h._sequence}
+	if i := h._positions[key]; i >= 0 {
+		if priority >= h._items[i].Priority {
+			return
+		}
+		h._cells[key] = c
+		h._items[i] = item
+		h.up(i)
+		return
+	}
+	h._cells[key] = c
+	h._items = append(h._items, item)
+	h._positions[key] = len(h._items) - 1
+	h.up(len(h._items) - 1)
+}
+
+// Pop the lowest priority item off of the heap
+func (h *Heap) Pop() (*Cell, error) {
+	if h.isEmpty() {
+		return nil, errors.New("Empty Heap")
+	}
+	key := h._items[0].Key
+	last := len(h._items) - 1
+	h.swap(0, last)
+	h._items = h._items[:last]
+	h._positions[key] = -1
+	if last > 0 {
+		h.down(0)
+	}
+	return h._cells[key], nil
+}
+
+func (h *Heap) less(i int, j int) bool {
+	a, b := h._items[i], h._items[j]
+	return a.Priority < b.Priority || (a.Priority == b.Priority && a.Sequence < b.Sequence)
+}
+
+func (h *Heap) swap(i int, j int) {
+	h._items[i], h._items[j] = h._items[j], h._items[i]
+	h._positions[h._items[i].Key] = i
+	h._positions[h._items[j].Key] = j
+}
+
+func (h *Heap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(i, parent) {
+			return
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+func (h *Heap) down(i int) {
+	for {
+		smallest := i
+		if left := 2*i + 1; left < len(h._items) && h.less(left, smallest) {
+			smallest = left
+		}
+		if right := 2*i + 2; right < len(h._items) && h.less(right, smallest) {
+			smallest = right
+		}
+		if smallest == i {
+			return
+		}
+		h.swap(i, smallest)
+		i = smallest
+	}
+}
+
+func (h *Heap) isEmpty() bool {
+	return len(h._items) == 0
+}
+
+func (h *Heap) isNotEmpty() bool {
+	return !h.isEmpty()
+}
+
+/*
+██       ██████   ██████
+██      ██    ██ ██
+██      ██    ██ ██   ███
+██      ██    ██ ██    ██
+███████  ██████   ██████
+*/
+
+// LogLevel is how much a Logger records
+type LogLevel int
+
+const (
+	// LogOff records nothing
+	LogOff LogLevel = iota
+	// LogInfo records a TurnRecord per turn
+	LogInfo
+	// LogDebug also records messages passed to log()
+	LogDebug
+)
+
+var logLevels = map[string]LogLevel{"off": LogOff, "info": LogInfo, "debug": LogDebug}
+
+// ParseLogLevel reads a LogLevel from its name
+func ParseLogLevel(name string) (LogLevel, error) {
+	if level, ok := logLevels[name]; ok {
+		return level, nil
+	}
+	return LogOff, fmt.Errorf("unknown log level %q, have off, info or debug", name)
+}
+
+// Logger writes one JSON TurnRecord per line. A nil Logger records nothing.
+type Logger struct {
+	Level    LogLevel
+	encoder  *json.Encoder
+	closer   io.Closer
+	messages []string
+}
+
+// TurnRecord is everything decided on one turn
+type TurnRecord struct {
+	Turn int `json:"turn"`
+	// Time from receiving the frame to deciding the moves
+	ElapsedMs float64       `json:"elapsed_ms"`
+	Fronts    []FrontRecord `json:"fronts,omitempty"`
+	// Strength of the body sent to border Segments short of it
+	Reinforced int          `json:"reinforced"`
+	Score      OwnerScore   `json:"score"`
+	Single     float64      `json:"single_score"`
+	Waste      Waste        `json:"waste"`
+	Moves      []MoveRecord `json:"moves"`
+	Messages   []string     `json:"messages,omitempty"`
+}
+
+// FrontRecord sums up a Front
+type FrontRecord struct {
+	Enemy         int     `json:"enemy"`
+	Cells         int     `json:"cells"`
+	Wall          int     `json:"wall"`
+	Strength      int     `json:"strength"`
+	EnemyStrength int     `json:"enemy_strength"`
+	Engaged       bool    `json:"engaged"`
+	Ready         bool    `json:"ready"`
+	Ratio         float64 `json:"ratio"`
+	Heat          int     `json:"heat"`
+}
+
+// MoveRecord is the move given to a cell and the strategy that chose it
+type MoveRecord struct {
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	Direction string `json:"direction"`
+	Strategy  string `json:"strategy"`
+}
+
+// logger is used by log(), see UseLogger
+var logger *Logger
+
+// UseLogger sets the Logger that log() keeps debug messages for, nil keeps none
+func UseLogger(l *Logger) {
+	logger = l
+}
+
+// NewLogger is a constructor for a Logger writing to writer
+func NewLogger(writer io.Writer, level LogLevel) *Logger {
+	return &Logger{Level: level, encoder: json.NewEncoder(writer)}
+}
+
+// OpenLogger creates the file at path for a Logger. No path or LogOff give a nil
+// Logger, which records nothing.
+func OpenLogger(path string, level LogLevel) (*Logger, error) {
+	if path == "" || level == LogOff {
+		return nil, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	l := NewLogger(f, level)
+	l.closer = f
+	return l, nil
+}
+
+// Enabled is true if the Logger records at level
+func (l *Logger) Enabled(level LogLevel) bool {
+	return l != nil && level != LogOff && l.Level >= level
+}
+
+// Debug keeps a message for the next TurnRecord
+func (l *Logger) Debug(a ...interface{}) {
+	if l.Enabled(LogDebug) {
+		l.messages = append(l.messages, strings.TrimSuffix(fmt.Sprintln(a...), "\n"))
+	}
+}
+
+// Turn writes the record of a turn decided by the bot
+func (l *Logger) Turn(b *Bot, turn int, elapsed time.Duration, moves hlt.MoveSet) error {
+	if !l.Enabled(LogInfo) {
+		return nil
+	}
+	strategies := make(map[hlt.Location]string, len(b.Decisions))
+	for _, decision := range b.Decisions {
+		strategies[decision.Move.Location] = decision.Strategy
+	}
+	score := ScoreOf(b.Cells, b.Owner)
+	record := TurnRecord{
+		Turn:      turn,
+		ElapsedMs: float64(elapsed) / float64(time.Millisecond),
+		Score:     score,
+		Single:    score.SingleScore(b.Params),
+		Waste:     b.Waste,
+		Moves:     make([]MoveRecord, 0, len(moves)),
+		Messages:  l.messages,
+	}
+	if b.Reinforcements != nil {
+		record.Reinforced = b.Reinforcements.Flow
+	}
+	for _, front := range b.Fronts {
+		record.Fronts = append(record.Fronts, FrontRecord{
+			Enemy:         front.Enemy,
+			Cells:         len(front.Cells),
+			Wall:          len(front.Wall),
+			Strength:      front.Strength,
+			EnemyStrength: front.EnemyStrength,
+			Engaged:       front.Engaged,
+			Ready:         front.Ready,
+			Ratio:         front.Ratio(),
+			Heat:          front.Heat,
+		})
+	}
+	for _, move := range moves {
+		record.Moves = append(record.Moves, MoveRecord{
+			X:         move.Location.X,
+			Y:         move.Location.Y,
+			Direction: DirectionString(move.Direction),
+			Strategy:  strategies[move.Location],
+		})
+	}
+	l.messages = nil
+	return l.encoder.Encode(record)
+}
+
+// Close the file opened by OpenLogger
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// log keeps a debug message for the next TurnRecord of the package logger
+func log(a ...interface{}) {
+	logger.Debug(a...)
+}
+
+type CellComp struct {
+	Cell  *Cell
+	Value int
+}
+
+type CellComps []CellComp
+
+func (slice CellComps) Len() int {
+	return len(slice)
+}
+
+func (slice CellComps) Less(i, j int) bool {
+	return slice[i].Value < slice[j].Value
+}
+
+func (slice CellComps) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+func SortNeighborsByDistanceToCell(cell *Cell, neighbors []*Cell) []*Cell {
+	sortCellComps := CellComps{}
+	for _, neighbor := range neighbors {
+		distance := cell.Cells.GameMap.GetDistance(cell.Location, neighbor.Location)
+		sortCellComps = append(sortCellComps, CellComp{Cell: neighbor, Value: distance})
+	}
+	sort.Sort(sortCellComps)
+	sortedCells := make([]*Cell, 0, len(neighbors))
+	for _, cellComp := range sortCellComps {
+		sortedCells = append(sortedCells, cellComp.Cell)
+	}
+	return sortedCells
+}
+
+// CellTest is a function used to filter or include cells that meet described criteria
+type CellTest func(cell *Cell) bool
+
+// CellCost is a function used to calculate the cost of moving to a cell from a another cell that itself has a cost to get to.
+type CellCost func(via *Cell, cell *Cell, field *FlowField) int
+
+// FlowField is a location and a grid of strength costs to that location
+type FlowField struct {
+	Destinations []*Cell
+	// Dimensions of the map the field covers
+	Width  int
+	Height int
+	// Costs, directions and whether a location was reached, indexed by y*Width + x
+	_field      []int
+	_directions []hlt.Direction
+	_reached    []bool
+}
+
+// PathString returns a string representing the pathing indicated by params
+func PathString(prev []hlt.Direction, next hlt.Direction) string {
+	dirs := make(map[hlt.Direction]bool)
+	dirs[next] = true
+	for _, dir := range prev {
+		dirs[dir] = true
+	}
+
+	north := dirs[hlt.NORTH] || next == hlt.NORTH
+	east := dirs[hlt.EAST] || next == hlt.EAST
+	south := dirs[hlt.SOUTH] || next == hlt.SOUTH
+	west := dirs[hlt.WEST] || next == hlt.WEST
+
+	if north && east && south && west {
+		return fmt.Sprintf("x")
+		// return fmt.Sprintf("%c", 0x253C) // ┼
+	}
+
+	if north && east && south && !west {
+		return fmt.Sprintf("%c", 0x251C) // ├
+	} else if north && east && !south && west {
+		return fmt.Sprintf("%c", 0x2534) // ┴
+	} else if north && !east && south && west {
+		return fmt.Sprintf("%c", 0x2524) // ┤
+	} else if !north && east && south && west {
+		return fmt.Sprintf("%c", 0x252C) // ┬
+	}
+
+	if north && east && !south && !west {
+		return fmt.Sprintf("%c", 0x2514) // └
+	} else if north && !east && !south && west {
+		return fmt.Sprintf("%c", 0x2518) // ┘
+	} else if !north && !east && south && west {
+		return fmt.Sprintf("%c", 0x2510) // ┐
+	} else if !north && east && south && !west {
+		return fmt.Sprintf("%c", 0x250C) // ┌
+	} else if north && !east && south && !west {
+		return fmt.Sprintf("%c", 0x2502) // │
+	} else if !north && east && !south && west {
+		return fmt.Sprintf("%c", 0x2500) // ─
+	}
+
+	if north {
+		return fmt.Sprintf("%c", 0x2575) // ╵
+	} else if east {
+		return fmt.Sprintf("%c", 0x2576) // ╶
+	} else if south {
+		return fmt.Sprintf("%c", 0x2577) // ╷
+	} else if west {
+		return fmt.Sprintf("%c", 0x2574) // ╴
+	}
+
+	return fmt.Sprintf(" ")
+	// return fmt.Sprintf("%c", 0x253C) // ┼
+}
+
+// FlowString converts the field into a string
+func FlowString(config int, ff *FlowField, c *Cells) string {
+	var buffer bytes.Buffer
+	for y := c.Y; y < c.Y+c.Height; y++ {
+		yf := y % c.GameMap.Height
+		for x := c.X; x < c.X+c.Width; x++ {
+			xf := x % c.GameMap.Width
+			location := hlt.NewLocation(xf, yf)
+			if ff.Reached(location) {
+				if config == 0 {
+					buffer.WriteString(fmt.Sprintf("%3d", ff.Cost(location)))
+				} else if config == 1 {
+					buffer.WriteString(fmt.Sprintf("%v  ", DirectionArrowString(ff.Direction(location))))
+				} else if config == 2 {
+					dirs := make([]hlt.Direction, 0, 4)
+					for _, direction := range hlt.CARDINALS {
+						if neighbor := c.GetLocation(location, direction); ff.Reached(neighbor) && ff.Direction(neighbor) == opposite(direction) {
+							dirs = append(dirs, direction)
+						}
+					}
+					if contains(c.Get(location.X, location.Y), ff.Destinations) {
+						buffer.WriteString(fmt.Sprintf("[%s]", PathString(dirs, ff.Direction(location))))
+					} else {
+						buffer.WriteString(fmt.Sprintf(" %s ", PathString(dirs, ff.Direction(location))))
+					}
+
+				}
+			} else {
+				buffer.WriteString("   ")
+			}
+		}
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
+
+// NewEmptyFlow is a constructor for a field that reaches nowhere
+func NewEmptyFlow() *FlowField {
+	return &FlowField{
+		Destinations: make([]*Cell, 0, 0),
+	}
+}
+
+// NewSizedFlow is a constructor for an empty field over a width x height map
+func NewSizedFlow(width int, height int) *FlowField {
+	return &FlowField{
+		Destinations: make([]*Cell, 0, 0),
+		Width:        width,
+		Height:       height,
+		_field:       make([]int, width*height),
+		_directions:  make([]hlt.Direction, width*height),
+		_reached:     make([]bool, width*height),
+	}
+}
+
+func (ff *FlowField) index(location hlt.Location) int {
+	return location.Y*ff.Width + location.X
+}
+
+// Reached is true if the field has a cost for the location
+func (ff *FlowField) Reached(location hlt.Location) bool {
+	return len(ff._reached) > 0 && ff._reached[ff.index(location)]
+}
+
+// Cost of reaching a destination from the location, 0 if the field never reached it
+func (ff *FlowField) Cost(location hlt.Location) int {
+	if !ff.Reached(location) {
+		return 0
+	}
+	return ff._field[ff.index(location)]
+}
+
+// Direction to move from the location towards a destination, STILL if the field never reached it
+func (ff *FlowField) Direction(location hlt.Location) hlt.Direction {
+	if !ff.Reached(location) {
+		return hlt.STILL
+	}
+	return ff._directions[ff.index(location)]
+}
+
+// Clone is a copy of the field that can be changed on its own
+func (ff *FlowField) Clone() *FlowField {
+	return &FlowField{
+		Destinations: ff.Destinations,
+		Width:        ff.Width,
+		Height:       ff.Height,
+		_field:       append([]int(nil), ff._field...),
+		_directions:  append([]hlt.Direction(nil), ff._directions...),
+		_reached:     append([]bool(nil), ff._reached...),
+	}
+}
+
+// Set the cost and direction for a location
+func (ff *FlowField) Set(location hlt.Location, cost int, direction hlt.Direction) {
+	i := ff.index(location)
+	ff._field[i] = cost
+	ff._directions[i] = direction
+	ff._reached[i] = true
+}
+
+// NewFlowField is a constructor. Limit cell can stop field generation beyond that cell.
+func NewFlowField(destinations []*Cell, cf CellCost) *FlowField {
+	if len(destinations) == 0 {
+		return NewEmptyFlow()
+	}
+	gameMap := destinations[0].Cells.GameMap
+	return newFlowField(destinations, cf, NewHeap(gameMap.Width, gameMap.Height))
+}
+
+// newFlowField expands the field outward from destinations in the order given by queue
+func newFlowField(destinations []*Cell, cf CellCost, queue PriorityQueue) *FlowField {
+	if len(destinations) == 0 {
+		return NewEmptyFlow()
+	}
+	gameMap := destinations[0].Cells.GameMap
+	field := NewSizedFlow(gameMap.Width, gameMap.Height)
+	field.Destinations = destinations
+	// limitCost := maxCost
+	for _, destination := range destinations {
+		field.Set(destination.Location, 0, hlt.STILL)
+		cost := cf(nil, destination, field)
+		field.Set(destination.Location, cost, hlt.STILL)
+		queue.PushPriority(destination, cost)
+	}
+	oppCount := 0
+	for queue.isNotEmpty() {
+		if cell, err := queue.Pop(); err == nil {
+			oppCount++
+			for dir, neighbor := range cell.Neighbors() {
+				direction := hlt.Direction(dir + 1)
+				newCost := cf(cell, neighbor, field)
+				if newCost < maxCost && (!field.Reached(neighbor.Location) || field.Cost(neighbor.Location) > newCost) {
+					field.Set(neighbor.Location, newCost, opposite(direction))
+					queue.PushPriority(neighbor, newCost)
+				}
+			}
+		}
+	}
+	return field
+}
+
+// NewBorderFlow is a constructor
+func NewBorderFlow(owner int, borders []*Cell) *FlowField {
+	return NewFlowField(borders, func(via *Cell, cell *Cell, field *FlowField) int {
+		if cell.Owner != owner {
+			return maxCost
+		}
+		if via != nil {
+			return field.Cost(via.Location) + cell.Production
+		}
+		return cell.Production
+	})
+}
+
+// NewBodyFlow is like Border, but drawn toward the nearest high production cell
+// we don't own. It takes no threats: a ThreatFlow reaches across most of the map,
+// so pulling on the nearest one drains the whole body to a single edge.
+// Reinforcements send strength to threatened Segments instead.
+func NewBodyFlow(owner int, borders []*Cell, highProds map[hlt.Location]*FlowField) *FlowField {
+	nearestProdOwner := owner
+	var nearestProdLoc hlt.Location
+	nearestProdCost := maxCost
+	for _, borderCell := range borders {
+		for location, flow := range highProds {
+			prodOwner := borderCell.Cells.Get(location.X, location.Y).Owner
+			if flow.Cost(borderCell.Location) < nearestProdCost {
+				nearestProdOwner = prodOwner
+				nearestProdLoc = location
+				nearestProdCost = flow.Cost(borderCell.Location)
+			}
+		}
+	}
+	return NewFlowField(borders, func(via *Cell, cell *Cell, field *FlowField) int {
+		if cell.Owner != owner {
+			return maxCost
+		}
+		if via != nil {
+			return field.Cost(via.Location) + cell.Production
+		}
+		if prodField, ok := highProds[nearestProdLoc]; ok && nearestProdOwner != owner {
+			return cell.Production + prodField.Cost(cell.Location)
+		}
+		return cell.Production
+	})
+}
+
+// NewProdFlow is a constructor
+func NewProdFlow(cell *Cell) *FlowField {
+	return NewFlowField([]*Cell{cell}, func(via *Cell, cell *Cell, field *FlowField) int {
+		if via != nil {
+			return field.Cost(via.Location) + cell.Production
+		}
+		return cell.Production
+	})
+}
+
+// NewStrengthFlow is a constructor. Produces a field where cost is the strength between
+// any cell and the one provided.
+func NewStrengthFlow(cell *Cell) *FlowField {
+	return NewStrengthFlowTo([]*Cell{cell})
+}
+
+// NewStrengthFlowTo is a constructor for a strength field leading to any of the cells
+func NewStrengthFlowTo(cells []*Cell) *FlowField {
+	return NewFlowField(cells, func(via *Cell, cell *Cell, field *FlowField) int {
+		if via != nil {
+			return field.Cost(via.Location) + cell.Strength
+		}
+		return cell.Strength
+	})
+}
+
+func contains(cell *Cell, cells []*Cell) bool {
+	for _, otherCell := range cells {
+		if otherCell == cell {
+			return true
+		}
+	}
+	return false
+}
+
+// NewThreatFlow is a constructor
+func NewThreatFlow(owner int, borders []*Cell) *FlowField {
+	field := NewFlowField(borders, func(via *Cell, cell *Cell, field *FlowField) int {
+		if contains(cell, borders) {
+			return 0 - cell.Strength
+		} else if cell.Owner == owner {
+			return maxCost
+		}
+		if via != nil {
+			if field.Cost(via.Location) < 0 {
+				return min(0, field.Cost(via.Location)+cell.Strength)
+			}
+			return maxCost
+		}
+		return 0 - cell.Strength
+	})
+	// invert value to have field represent remaining strength
+	for i, reached := range field._reached {
+		if reached {
+			field._field[i] = 0 - field._field[i]
+		}
+	}
+	return field
+}
+
+func ThreatFlows(cells *Cells) map[int]*FlowField {
+	fields := make(map[int]*FlowField)
+	for owner, ownedCells := range cells.ByOwner {
+		fields[owner] = NewThreatFlow(owner, ownedCells.BorderCells())
+		// log(FlowString(0, fields[owner], cells))
+	}
+	return fields
+}
+
+// MergeFlows combines fields into one leading to the cheapest destination of any of them
+func MergeFlows(fields []*FlowField) *FlowField {
+	if len(fields) == 0 {
+		return NewEmptyFlow()
+	}
+	merged := NewSizedFlow(fields[0].Width, fields[0].Height)
+	for _, field := range fields {
+		merged.Destinations = append(merged.Destinations, field.Destinations...)
+		for i, reached := range field._reached {
+			if reached && (!merged._reached[i] || field._field[i] < merged._field[i]) {
+				merged._field[i] = field._field[i]
+				merged._directions[i] = field._directions[i]
+				merged._reached[i] = true
+			}
+		}
+	}
+	return merged
+}
+
+// Flow finds one of the bot's fields by name: body, prod for the merged
+// ToHighestProd fields, or threat:<owner>
+func (b *Bot) Flow(name string) (*FlowField, error) {
+	switch {
+	case name == "body":
+		return b.BodyFlow, nil
+	case name == "prod":
+		fields := make([]*FlowField, 0, len(b.ToHighestProd))
+		for _, field := range b.ToHighestProd {
+			fields = append(fields, field)
+		}
+		return MergeFlows(fields), nil
+	case strings.HasPrefix(name, "threat:"):
+		owner, err := strconv.Atoi(strings.TrimPrefix(name, "threat:"))
+		if err != nil {
+			return nil, fmt.Errorf("flow %q: %v", name, err)
+		}
+		if field, ok := b.ThreatFlows[owner]; ok {
+			return field, nil
+		}
+		return NewEmptyFlow(), nil
+	}
+	return nil, fmt.Errorf("unknown flow %q, have body, prod or threat:<owner>", name)
+}
+
+/*
+██████  ███████  ██████  ██  ██████  ███    ██
+██   ██ ██      ██       ██ ██    ██ ████   ██
+██████  █████   ██   ███ ██ ██    ██ ██ ██  ██
+██   ██ ██      ██    ██ ██ ██    ██ ██  ██ ██
+██   ██ ███████  ██████  ██  ██████  ██   ████
+*/
+
+// regionTargets is how many of the best ranked regions a Bot expands towards
+const regionTargets = 8
+
+// regionRerank is how many turns a Bot keeps its Targets before finding and
+// ranking the regions again from the territory it holds by then
+const regionRerank = 10
+
+// Region is a connected cluster of neutral cells producing at least the
+// threshold FindRegions was given
+type Region struct {
+	Cells      []*Cell
+	Production int
+	Strength   int
+	// CaptureCost is the strength spent on the way from a starting location plus
+	// the strength of every cell in the region
+	CaptureCost int
+	// Flow leads to the cells of the region still unowned
+	Flow *FlowField
+}
+
+// Density is the production per strength of the region
+func (r *Region) Density() float64 {
+	return float64(r.Production) / float64(max(1, r.Strength))
+}
+
+// Value is the production gained per strength spent capturing the region
+func (r *Region) Value() float64 {
+	return float64(r.Production) / float64(max(1, r.CaptureCost))
+}
+
+// Uncaptured is the cells of the region still unowned. Cells an enemy took are
+// left to the Fronts.
+func (r *Region) Uncaptured() []*Cell {
+	cells := make([]*Cell, 0, len(r.Cells))
+	for _, cell := range r.Cells {
+		if cell.Owner == unowned {
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+// Remaining is the production and strength of the cells still unowned
+func (r *Region) Remaining() (int, int) {
+	production, strength := 0, 0
+	for _, cell := range r.Cells {
+		if cell.Owner == unowned {
+			production += cell.Production
+			strength += cell.Strength
+		}
+	}
+	return production, strength
+}
+
+// RegionThreshold is the production halfway between the average and the highest
+func RegionThreshold(cells *Cells) int {
+	return (cells.AvgProduction + cells.MaxProduction + 1) / 2
+}
+
+// FindRegions clusters the neutral cells producing at least threshold into
+// Regions of neighbors, densest first
+func FindRegions(cells *Cells, threshold int) []*Region {
+	regions := make([]*Region, 0)
+	seen := make([]bool, len(cells.Contents))
+	member := func(cell *Cell) bool {
+		return cell != nil && cell.Owner == unowned && cell.Production >= threshold
+	}
+	for i := range cells.Contents {
+		if seen[i] || !member(&cells.Contents[i]) {
+			continue
+		}
+		region := &Region{Flow: NewEmptyFlow()}
+		seen[i] = true
+		queue := []*Cell{&cells.Contents[i]}
+		for len(queue) > 0 {
+			cell := queue[0]
+			queue = queue[1:]
+			region.Cells = append(region.Cells, cell)
+			region.Production += cell.Production
+			region.Strength += cell.Strength
+			for _, direction := range hlt.CARDINALS {
+				neighbor := cells.GetCell(cell.Location, direction)
+				if !member(neighbor) {
+					continue
+				}
+				if j := cells.Index(neighbor.X, neighbor.Y); !seen[j] {
+					seen[j] = true
+					queue = append(queue, neighbor)
+				}
+			}
+		}
+		regions = append(regions, region)
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		return regions[i].Density() > regions[j].Density()
+	})
+	return regions
+}
+
+// RankRegions sets the CaptureCost of every region from the nearest of origins
+// and sorts them by Value, best first
+func RankRegions(cells *Cells, regions []*Region, origins []*Cell) []*Region {
+	if len(origins) == 0 {
+		return regions
+	}
+	// we hold the origins, every other cell on the way costs its strength
+	fromOrigins := NewFlowField(origins, func(via *Cell, cell *Cell, field *FlowField) int {
+		if via != nil {
+			return field.Cost(via.Location) + cell.Strength
+		}
+		return 0
+	})
+	for _, region := range regions {
+		path := maxCost
+		for _, cell := range region.Cells {
+			// the strength of the cell itself is paid for below
+			if fromOrigins.Reached(cell.Location) {
+				path = min(path, fromOrigins.Cost(cell.Location)-cell.Strength)
+			}
+		}
+		region.CaptureCost = path + region.Strength
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		return regions[i].Value() > regions[j].Value()
+	})
+	return regions
+}
+
+// UpdateRegionFlows leads each region's Flow to its cells still unowned
+func UpdateRegionFlows(regions []*Region) {
+	for _, region := range regions {
+		if uncaptured := region.Uncaptured(); len(uncaptured) > 0 {
+			region.Flow = NewStrengthFlowTo(uncaptured)
+		} else {
+			region.Flow = NewEmptyFlow()
+		}
+	}
+}
+
+/*
+██████  ██       █████  ███    ██
+██   ██ ██      ██   ██ ████   ██
+██████  ██      ███████ ██ ██  ██
+██      ██      ██   ██ ██  ██ ██
+██      ███████ ██   ██ ██   ████
+*/
+
+// planHorizon is the default Params.PlanHorizon
+const planHorizon = 10
+
+// Capture is a neutral cell the Plan takes Turn turns from now. Attackers are
+// the owned neighbors moving in, Feeders the cells pooling their strength into
+// the attackers along BodyFlow this turn.
+type Capture struct {
+	Target    *Cell
+	Turn      int
+	Attackers []*Cell
+	Feeders   []*Cell
+}
+
+// Payback is the turns of the target's production it takes to earn back the
+// strength spent taking it
+func (c *Capture) Payback() float64 {
+	return payback(c.Target)
+}
+
+func payback(cell *Cell) float64 {
+	return float64(cell.Strength) / float64(cell.Production)
+}
+
+// planStrategy names the Decisions following a Plan's orders, mergeStrategy
+// those following the merges made without the planner
+const planStrategy = "plan"
+const mergeStrategy = "merge"
+
+// Plan schedules the capture of the neutral cells next to a Bot's territory,
+// quickest payback first, and the move each cell involved makes this turn
+type Plan struct {
+	Captures []*Capture
+	Orders   map[hlt.Location]hlt.Direction
+}
+
+// Order is the move the plan gives the cell at location, if any
+func (p *Plan) Order(location hlt.Location) (hlt.Direction, bool) {
+	if p == nil {
+		return hlt.STILL, false
+	}
+	direction, ok := p.Orders[location]
+	return direction, ok
+}
+
+// NewPlan is a constructor. Targets next to an enemy are left to the strategies,
+// as are targets that would take more than horizon turns and cells at engaged
+// Fronts.
+func NewPlan(b *Bot, horizon int) *Plan {
+	plan := &Plan{Captures: make([]*Capture, 0), Orders: make(map[hlt.Location]hlt.Direction)}
+	reserved := b.engagedCells()
+	for _, target := range b.Frontier() {
+		attackers, directions := b.attackers(target, reserved)
+		if len(attackers) == 0 {
+			continue
+		}
+		if capture := b.scheduleCapture(target, attackers, reserved, horizon); capture != nil {
+			plan.add(b, capture, directions, reserved)
+		}
+	}
+	return plan
+}
+
+// NewMergePlan is a constructor for a Plan of only the captures no single cell
+// can make this turn, each taken by a MergeGroup moving in together. Cells at
+// engaged Fronts are left to the strategies.
+func NewMergePlan(b *Bot) *Plan {
+	plan := &Plan{Captures: make([]*Capture, 0), Orders: make(map[hlt.Location]hlt.Direction)}
+	reserved := b.engagedCells()
+	for _, target := range b.Frontier() {
+		attackers, directions := b.attackers(target, reserved)
+		if len(attackers) < 2 || attackers[0].Strength > target.Strength {
+			continue
+		}
+		if group := MergeGroup(target, attackers, b.Owner); group != nil {
+			plan.add(b, &Capture{Target: target, Turn: 0, Attackers: group}, directions, reserved)
+		}
+	}
+	return plan
+}
+
+// engagedCells is the cells at hot Fronts, reserved for the strategies before
+// any capture is planned
+func (b *Bot) engagedCells() map[hlt.Location]bool {
+	engaged := make(map[hlt.Location]bool)
+	for location, front := range b.fronts {
+		if front.Hot() {
+			engaged[location] = true
+		}
+	}
+	return engaged
+}
+
+// add gives the cells of a capture their orders and reserves them
+func (p *Plan) add(b *Bot, capture *Capture, directions map[hlt.Location]hlt.Direction, reserved map[hlt.Location]bool) {
+	for _, attacker := range capture.Attackers {
+		reserved[attacker.Location] = true
+		if capture.Turn == 0 {
+			p.Orders[attacker.Location] = directions[attacker.Location]
+		} else {
+			p.Orders[attacker.Location] = hlt.STILL
+		}
+	}
+	for _, feeder := range capture.Feeders {
+		reserved[feeder.Location] = true
+		p.Orders[feeder.Location] = b.BodyFlow.Direction(feeder.Location)
+	}
+	p.Captures = append(p.Captures, capture)
+}
+
+// attackers are the owned neighbors of target not reserved yet, strongest first,
+// and the way each moves into the target
+func (b *Bot) attackers(target *Cell, reserved map[hlt.Location]bool) ([]*Cell, map[hlt.Location]hlt.Direction) {
+	attackers := make([]*Cell, 0, 4)
+	directions := make(map[hlt.Location]hlt.Direction)
+	for _, direction := range hlt.CARDINALS {
+		neighbor := b.Cells.GetCell(target.Location, direction)
+		if neighbor != nil && neighbor.Owner == b.Owner && !reserved[neighbor.Location] {
+			attackers = append(attackers, neighbor)
+			directions[neighbor.Location] = opposite(direction)
+		}
+	}
+	sort.SliceStable(attackers, func(i, j int) bool {
+		return attackers[i].Strength > attackers[j].Strength
+	})
+	return attackers, directions
+}
+
+// MergeGroup is the set of attackers whose merged force beats the target while
+// moving the least strength, fewest cells on a tie, or nil when none does. Forces
+// merge as in Cells.Simulate, so strength over maxStrength is lost.
+func MergeGroup(target *Cell, attackers []*Cell, owner int) []*Cell {
+	var group []*Cell
+	groupStrength := 0
+	for subset := 1; subset < 1<<len(attackers); subset++ {
+		forces := Forces{}
+		members := make([]*Cell, 0, len(attackers))
+		strength := 0
+		for i, attacker := range attackers {
+			if subset&(1<<i) != 0 {
+				forces.Add(target.Location, owner, attacker.Strength)
+				members = append(members, attacker)
+				strength += attacker.Strength
+			}
+		}
+		if forces[target.Location][owner] <= target.Strength {
+			continue
+		}
+		if group == nil || strength < groupStrength || (strength == groupStrength && len(members) < len(group)) {
+			group = members
+			groupStrength = strength
+		}
+	}
+	return group
+}
+
+// scheduleCapture finds the earliest turn attackers take target: now with a
+// MergeGroup, next turn with feeders pooled into them, or once
+// the attackers' production alone is enough. Strength is capped at maxStrength.
+func (b *Bot) scheduleCapture(target *Cell, attackers []*Cell, reserved map[hlt.Location]bool, horizon int) *Capture {
+	if group := MergeGroup(target, attackers, b.Owner); group != nil {
+		return &Capture{Target: target, Turn: 0, Attackers: group}
+	}
+	// later turns, each attacker's strength after turns of production
+	grown := func(turns int) int {
+		total := 0
+		for _, attacker := range attackers {
+			total += min(maxStrength, attacker.Strength+attacker.Production*turns)
+		}
+		return total
+	}
+	if grown(1) <= target.Strength {
+		pooled := 0
+		feeders := make([]*Cell, 0)
+		for _, attacker := range attackers {
+			strength := attacker.Strength + attacker.Production
+			for _, feeder := range b.feeders(attacker, reserved) {
+				feeders = append(feeders, feeder)
+				strength += feeder.Strength
+			}
+			pooled += min(maxStrength, strength)
+		}
+		if pooled > target.Strength {
+			return &Capture{Target: target, Turn: 1, Attackers: attackers, Feeders: feeders}
+		}
+	}
+	for turns := 1; turns <= horizon; turns++ {
+		if grown(turns) > target.Strength {
+			return &Capture{Target: target, Turn: turns, Attackers: attackers}
+		}
+	}
+	return nil
+}
+
+// feeders are the owned neighbors of a cell whose BodyFlow leads into it and
+// that are not reserved by the Plan already
+func (b *Bot) feeders(cell *Cell, reserved map[hlt.Location]bool) []*Cell {
+	feeders := make([]*Cell, 0, 3)
+	for _, direction := range hlt.CARDINALS {
+		neighbor := b.Cells.GetCell(cell.Location, direction)
+		if neighbor == nil || neighbor.Owner != b.Owner || reserved[neighbor.Location] || neighbor.Strength == 0 {
+			continue
+		}
+		if b.BodyFlow.Reached(neighbor.Location) && b.BodyFlow.Direction(neighbor.Location) == opposite(direction) {
+			feeders = append(feeders, neighbor)
+		}
+	}
+	return feeders
+}
+
+// Frontier is the producing neutral cells next to the Bot's territory and no
+// enemy's, quickest Payback first
+func (b *Bot) Frontier() []*Cell {
+	frontier := make([]*Cell, 0)
+	seen := make(map[hlt.Location]bool)
+	for _, cell := range b.BorderCells() {
+		for _, direction := range hlt.CARDINALS {
+			neighbor := b.Cells.GetCell(cell.Location, direction)
+			if neighbor == nil || neighbor.Owner != unowned || neighbor.Production == 0 || seen[neighbor.Location] {
+				continue
+			}
+			seen[neighbor.Location] = true
+			if !b.nextToEnemy(neighbor) {
+				frontier = append(frontier, neighbor)
+			}
+		}
+	}
+	sort.SliceStable(frontier, func(i, j int) bool {
+		return payback(frontier[i]) < payback(frontier[j])
+	})
+	return frontier
+}
+
+// nextToEnemy is true when a neighbor of the cell belongs to another player
+func (b *Bot) nextToEnemy(cell *Cell) bool {
+	for _, direction := range hlt.CARDINALS {
+		if neighbor := b.Cells.GetCell(cell.Location, direction); neighbor != nil && neighbor.Owner != unowned && neighbor.Owner != b.Owner {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+██     ██  █████  ███████ ████████ ███████
+██     ██ ██   ██ ██         ██    ██
+██  █  ██ ███████ ███████    ██    █████
+██ ███ ██ ██   ██      ██    ██    ██
+ ███ ███  ██   ██ ███████    ██    ███████
+*/
+
+// capStrategy names the Decisions PreventWaste changed
+const capStrategy = "cap"
+
+// wastePasses is how many times PreventWaste goes over the moves
+const wastePasses = 3
+
+// Waste is the strength a turn's moves lose to the maxStrength cap
+type Waste struct {
+	// Merged is lost where pieces merge over the cap
+	Merged int `json:"merged"`
+	// Produced is production lost by STILL cells already near the cap
+	Produced int `json:"produced"`
+	// Avoided is the waste PreventWaste took out of the moves
+	Avoided int `json:"avoided"`
+}
+
+// Total is the strength lost
+func (w Waste) Total() int {
+	return w.Merged + w.Produced
+}
+
+// wasteModel is the strength our cells bring to each position in Cells, as
+// STILL cells produce before pieces merge
+type wasteModel struct {
+	cells    *Cells
+	incoming []int
+}
+
+// contribution is the strength a cell brings to its destination
+func contribution(cell *Cell, direction hlt.Direction) int {
+	if direction == hlt.STILL {
+		return min(maxStrength, cell.Strength+cell.Production)
+	}
+	return cell.Strength
+}
+
+// produced is the production a cell loses to the cap
+func produced(cell *Cell, direction hlt.Direction) int {
+	if direction == hlt.STILL {
+		return max(0, cell.Strength+cell.Production-maxStrength)
+	}
+	return 0
+}
+
+func merged(strength int) int {
+	return max(0, strength-maxStrength)
+}
+
+// index is the position in Cells of the cell moved in direction
+func (m *wasteModel) index(cell *Cell, direction hlt.Direction) int {
+	location := m.cells.GetLocation(cell.Location, direction)
+	return m.cells.Index(location.X, location.Y)
+}
+
+func (m *wasteModel) add(cell *Cell, direction hlt.Direction) {
+	m.incoming[m.index(cell, direction)] += contribution(cell, direction)
+}
+
+func (m *wasteModel) move(cell *Cell, from hlt.Direction, to hlt.Direction) {
+	m.incoming[m.index(cell, from)] -= contribution(cell, from)
+	m.add(cell, to)
+}
+
+// saving is the waste taken out by moving cell in to instead of from, negative
+// when it adds waste
+func (m *wasteModel) saving(cell *Cell, from hlt.Direction, to hlt.Direction) int {
+	fromIndex, toIndex := m.index(cell, from), m.index(cell, to)
+	wasted := func(direction hlt.Direction) int {
+		total := produced(cell, direction) + merged(m.incoming[fromIndex])
+		if toIndex != fromIndex {
+			total += merged(m.incoming[toIndex])
+		}
+		return total
+	}
+	before := wasted(from)
+	m.move(cell, from, to)
+	after := wasted(to)
+	m.move(cell, to, from)
+	return before - after
+}
+
+// PreventWaste holds or reroutes moves so less strength is lost to the cap,
+// where pieces merge over maxStrength or STILL cells produce past it. Cells
+// only reroute into our own cells and never start a capture; moves into a
+// neutral are held only when the rest still take it. Cells next to an enemy
+// are left alone. It returns the new moves and the Waste left in them.
+func (b *Bot) PreventWaste(moves hlt.MoveSet) (hlt.MoveSet, Waste) {
+	model := &wasteModel{cells: b.Cells, incoming: make([]int, len(b.Cells.Contents))}
+	owned := make([]*Cell, len(moves))
+	for i, move := range moves {
+		cell := b.Cells.Get(move.Location.X, move.Location.Y)
+		if cell == nil || cell.Owner != b.Owner || b.Cells.GetCell(move.Location, move.Direction) == nil {
+			continue
+		}
+		owned[i] = cell
+		model.add(cell, move.Direction)
+	}
+	waste := b.waste(moves, owned, model)
+	before := waste.Total()
+	result := append(hlt.MoveSet{}, moves...)
+	for pass := 0; pass < wastePasses; pass++ {
+		improved := false
+		for i, move := range result {
+			cell := owned[i]
+			if cell == nil || b.nextToEnemy(cell) {
+				continue
+			}
+			best, bestSaving := move.Direction, 0
+			for _, direction := range hlt.Directions {
+				if direction == move.Direction || !b.wasteOption(cell, move.Direction, direction, model) {
+					continue
+				}
+				if saving := model.saving(cell, move.Direction, direction); saving > bestSaving {
+					best, bestSaving = direction, saving
+				}
+			}
+			if best != move.Direction {
+				model.move(cell, move.Direction, best)
+				result[i].Direction = best
+				improved = true
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	waste = b.waste(result, owned, model)
+	waste.Avoided = before - waste.Total()
+	return result, waste
+}
+
+// wasteOption is true when PreventWaste may send cell in to instead of from
+func (b *Bot) wasteOption(cell *Cell, from hlt.Direction, to hlt.Direction, model *wasteModel) bool {
+	if to != hlt.STILL {
+		destination := b.Cells.GetCell(cell.Location, to)
+		if destination == nil || destination.Owner != b.Owner || b.nextToEnemy(destination) {
+			return false
+		}
+	}
+	if from == hlt.STILL {
+		return true
+	}
+	target := b.Cells.GetCell(cell.Location, from)
+	if target.Owner == b.Owner {
+		return true
+	}
+	// the others moving in must still take the neutral
+	index := b.Cells.Index(target.X, target.Y)
+	return target.Owner == unowned && min(maxStrength, model.incoming[index]-cell.Strength) > target.Strength
+}
+
+// waste sums what the moves lose to the cap in the model
+func (b *Bot) waste(moves hlt.MoveSet, owned []*Cell, model *wasteModel) Waste {
+	waste := Waste{}
+	for i, move := range moves {
+		if owned[i] != nil {
+			waste.Produced += produced(owned[i], move.Direction)
+		}
+	}
+	for _, incoming := range model.incoming {
+		waste.Merged += merged(incoming)
+	}
+	return waste
+}
+
+/*
+██     ██  █████  ██      ██
+██     ██ ██   ██ ██      ██
+██  █  ██ ███████ ██      ██
+██ ███ ██ ██   ██ ██      ██
+ ███ ███  ██   ██ ███████ ███████
+*/
+
+// frontReach is how far around a Front the strength of both sides is counted,
+// and how far from a hot Front cells fight
+const frontReach = 3
+
+// wallRatio is the default Params.WallRatio
+const wallRatio = 2.0
+
+// frontMemory is how many turns of force ratios a Front remembers
+const frontMemory = 8
+
+// frontCooldown is how many turns a Front stays hot after it was last Engaged
+const frontCooldown = 5
+
+// Front is a stretch of our border facing one enemy: touching it, behind a wall
+// of neutral cells next to both of us, or within reach of its ThreatFlow.
+// Taking a wall cell puts our strength next to the enemy, inviting overkill, so
+// the wall is left standing until the Front is Ready.
+type Front struct {
+	Enemy int
+	// Cells of our border facing the enemy
+	Cells []*Cell
+	// Wall is the neutral cells between the Cells and the enemy
+	Wall []*Cell
+	// Area is every cell within frontReach of the Cells and the Wall
+	Area []*Cell
+	// Strength of ours and of the enemy in the Area
+	Strength      int
+	EnemyStrength int
+	// Engaged once the enemy touches the Cells or either side lost a site in the
+	// Area since the previous frame. Overkill leaves the sites it clears neutral,
+	// so a fight through the wall rarely shows as touching.
+	Engaged bool
+	// Ready once our Strength is WallRatio times the enemy's
+	Ready bool
+	// Ratios of our Strength to the enemy's on each turn the Front was seen,
+	// oldest first and at most frontMemory of them
+	Ratios []float64
+	// Heat is frontCooldown on a turn the Front is Engaged, cooling by one each
+	// turn it is not
+	Heat int
+}
+
+// FindFronts is the Fronts of owner against every enemy. Border cells facing
+// the same enemy make one Front while they are a step apart, diagonals included.
+// previous is the frame before cells, nil on the first.
+func FindFronts(cells *Cells, previous *Cells, owner int, threats map[int]*FlowField, ratio float64) []*Front {
+	ownedCells, ok := cells.ByOwner[owner]
+	if !ok {
+		return nil
+	}
+	// the border cells facing each enemy, and the enemies they touch or are walled from
+	facing := make(map[int][]*Cell)
+	touching := make(map[hlt.Location]map[int]bool)
+	walls := make(map[int]map[hlt.Location]*Cell)
+	face := func(enemy int, cell *Cell) {
+		if _, ok := touching[cell.Location]; !ok {
+			touching[cell.Location] = make(map[int]bool)
+		}
+		if _, ok := touching[cell.Location][enemy]; !ok {
+			touching[cell.Location][enemy] = false
+			facing[enemy] = append(facing[enemy], cell)
+		}
+	}
+	for _, cell := range ownedCells.BorderCells() {
+		// the enemy's strength can get here through whatever is in between
+		for enemy, threat := range threats {
+			if enemy != unowned && enemy != owner && threat.Reached(cell.Location) {
+				face(enemy, cell)
+			}
+		}
+		for _, direction := range hlt.CARDINALS {
+			neighbor := cells.GetCell(cell.Location, direction)
+			if neighbor == nil || neighbor.Owner == owner {
+				continue
+			}
+			if neighbor.Owner != unowned {
+				face(neighbor.Owner, cell)
+				touching[cell.Location][neighbor.Owner] = true
+				continue
+			}
+			for _, enemy := range enemiesNextTo(cells, neighbor, owner) {
+				face(enemy, cell)
+				if _, ok := walls[enemy]; !ok {
+					walls[enemy] = make(map[hlt.Location]*Cell)
+				}
+				walls[enemy][neighbor.Location] = neighbor
+			}
+		}
+	}
+	enemies := make([]int, 0, len(facing))
+	for enemy := range facing {
+		enemies = append(enemies, enemy)
+	}
+	sort.Ints(enemies)
+
+	fronts := make([]*Front, 0)
+	for _, enemy := range enemies {
+		members := make(map[hlt.Location]bool, len(facing[enemy]))
+		for _, cell := range facing[enemy] {
+			members[cell.Location] = true
+		}
+		seen := make(map[hlt.Location]bool)
+		for _, start := range facing[enemy] {
+			if seen[start.Location] {
+				continue
+			}
+			seen[start.Location] = true
+			front := &Front{Enemy: enemy}
+			queue := []*Cell{start}
+			for len(queue) > 0 {
+				cell := queue[0]
+				queue = queue[1:]
+				front.Cells = append(front.Cells, cell)
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						location := cells.GetSafeLocation(cell.X+dx, cell.Y+dy)
+						if members[location] && !seen[location] {
+							seen[location] = true
+							queue = append(queue, cells.Get(location.X, location.Y))
+						}
+					}
+				}
+			}
+			front.measure(cells, previous, owner, walls[enemy], touching, ratio)
+			fronts = append(fronts, front)
+		}
+	}
+	return fronts
+}
+
+// measure finds the Wall and Area of a Front from its Cells, then sizes up both sides
+func (f *Front) measure(cells *Cells, previous *Cells, owner int, walls map[hlt.Location]*Cell, touching map[hlt.Location]map[int]bool, ratio float64) {
+	distances := make(map[hlt.Location]int)
+	queue := make([]*Cell, 0)
+	visit := func(cell *Cell, distance int) {
+		if _, ok := distances[cell.Location]; !ok {
+			distances[cell.Location] = distance
+			queue = append(queue, cell)
+		}
+	}
+	for _, cell := range f.Cells {
+		visit(cell, 0)
+		if touching[cell.Location][f.Enemy] {
+			f.Engaged = true
+		}
+		for _, direction := range hlt.CARDINALS {
+			if wall, ok := walls[cells.GetLocation(cell.Location, direction)]; ok {
+				if _, ok := distances[wall.Location]; !ok {
+					f.Wall = append(f.Wall, wall)
+				}
+				visit(wall, 0)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		f.Area = append(f.Area, cell)
+		if previous != nil {
+			before := previous.Get(cell.X, cell.Y)
+			if (before.Owner == owner || before.Owner == f.Enemy) && cell.Owner != before.Owner {
+				f.Engaged = true
+			}
+		}
+		switch cell.Owner {
+		case owner:
+			f.Strength += cell.Strength
+		case f.Enemy:
+			f.EnemyStrength += cell.Strength
+		}
+		if distances[cell.Location] == frontReach {
+			continue
+		}
+		for _, direction := range hlt.CARDINALS {
+			if neighbor := cells.GetCell(cell.Location, direction); neighbor != nil {
+				visit(neighbor, distances[cell.Location]+1)
+			}
+		}
+	}
+	f.Ready = f.Strength > 0 && float64(f.Strength) >= ratio*float64(f.EnemyStrength)
+}
+
+// Ratio is our Strength over the enemy's this turn
+func (f *Front) Ratio() float64 {
+	return float64(f.Strength) / float64(max(1, f.EnemyStrength))
+}
+
+// Trend is how much the Ratio moved over the remembered turns, negative while
+// the enemy gains on us
+func (f *Front) Trend() float64 {
+	if len(f.Ratios) < 2 {
+		return 0
+	}
+	return f.Ratios[len(f.Ratios)-1] - f.Ratios[0]
+}
+
+// Hot is true while the Front was Engaged in the last frontCooldown turns, or
+// while the enemy outnumbers us there and keeps gaining, as it does massing for
+// an attack. It is quiet otherwise.
+func (f *Front) Hot() bool {
+	return f.Heat > 0 || (f.Ratio() < 1 && f.Trend() < 0)
+}
+
+// TrackFronts carries the Ratios and Heat of last turn's Fronts over to this
+// turn's. Each Front continues the one against the same enemy whose Area it
+// overlaps most, so a Front that splits continues in both halves.
+func TrackFronts(previous []*Front, fronts []*Front) {
+	for _, front := range fronts {
+		area := make(map[hlt.Location]bool, len(front.Area))
+		for _, cell := range front.Area {
+			area[cell.Location] = true
+		}
+		var match *Front
+		best := 0
+		for _, before := range previous {
+			if before.Enemy != front.Enemy {
+				continue
+			}
+			overlap := 0
+			for _, cell := range before.Area {
+				if area[cell.Location] {
+					overlap++
+				}
+			}
+			if overlap > best {
+				match, best = before, overlap
+			}
+		}
+		front.Ratios = make([]float64, 0, frontMemory)
+		if match != nil {
+			front.Ratios = append(front.Ratios, match.Ratios[max(0, len(match.Ratios)-frontMemory+1):]...)
+			front.Heat = max(0, match.Heat-1)
+		}
+		front.Ratios = append(front.Ratios, front.Ratio())
+		if front.Engaged {
+			front.Heat = frontCooldown
+		}
+	}
+}
+
+// enemiesNextTo are the players other than owner with a cell next to cell
+func enemiesNextTo(cells *Cells, cell *Cell, owner int) []int {
+	enemies := make([]int, 0, 1)
+	for _, direction := range hlt.CARDINALS {
+		neighbor := cells.GetCell(cell.Location, direction)
+		if neighbor == nil || neighbor.Owner == unowned || neighbor.Owner == owner {
+			continue
+		}
+		known := false
+		for _, enemy := range enemies {
+			known = known || enemy == neighbor.Owner
+		}
+		if !known {
+			enemies = append(enemies, neighbor.Owner)
+		}
+	}
+	return enemies
+}
+
+// updateFronts finds the Fronts, continuing last turn's, then which Front each
+// cell is at and the wall cells held
+func (b *Bot) updateFronts() {
+	previous := b.Fronts
+	b.Fronts = FindFronts(b.Cells, b.previous, b.Owner, b.ThreatFlows, b.Params.orDefault().WallRatio)
+	TrackFronts(previous, b.Fronts)
+	b.fronts = make(map[hlt.Location]*Front)
+	b.walls = make(map[hlt.Location]bool)
+	for _, front := range b.Fronts {
+		for _, cell := range front.Area {
+			if at, ok := b.fronts[cell.Location]; !ok || front.Heat > at.Heat {
+				b.fronts[cell.Location] = front
+			}
+		}
+		if !front.Hot() && !front.Ready {
+			for _, cell := range front.Wall {
+				b.walls[cell.Location] = true
+			}
+		}
+	}
+}
+
+// FrontAt is the hottest Front whose Area holds the cell, nil away from every Front
+func (b *Bot) FrontAt(cell *Cell) *Front {
+	return b.fronts[cell.Location]
+}
+
+// Engaged is true when the cell is at a hot Front
+func (b *Bot) Engaged(cell *Cell) bool {
+	front := b.FrontAt(cell)
+	return front != nil && front.Hot()
+}
+
+// Walled is true when the cell is in the Wall of a quiet Front that is not
+// Ready to break it, so strategies leave it be
+func (b *Bot) Walled(cell *Cell) bool {
+	return b.walls[cell.Location]
+}
+
+/*
+██████  ███████ ██ ███    ██ ███████  ██████  ██████   ██████ ███████
+██   ██ ██      ██ ████   ██ ██      ██    ██ ██   ██ ██      ██
+██████  █████   ██ ██ ██  ██ █████   ██    ██ ██████  ██      █████
+██   ██ ██      ██ ██  ██ ██ ██      ██    ██ ██   ██ ██      ██
+██   ██ ███████ ██ ██   ████ ██       ██████  ██   ██  ██████ ███████
+*/
+
+// segmentSize is the most border cells an expanding Segment is made of
+const segmentSize = 8
+
+// reinforceSources is the most body cells sent to Segments, strongest first
+const reinforceSources = 400
+
+// reinforceSegments is the most Segments asking for strength. Each costs a
+// FlowField and an edge from every source.
+const reinforceSegments = 32
+
+// Segment is a stretch of our border asking the body for strength: a Front
+// until it is Ready to break its wall, or neutral cells to capture
+type Segment struct {
+	// Cells of our border in the Segment
+	Cells []*Cell
+	// Front the Segment holds, nil for one expanding into neutrals
+	Front *Front
+	// Demand is the strength the Segment is short of
+	Demand int
+	// Field leads through our territory to the Cells
+	Field *FlowField
+}
+
+// FindSegments splits the border of owner into a Segment for every Front and
+// runs of at most segmentSize other border cells. A Front asks for ratio times
+// the enemy's strength there (at least as much as the enemy), the others for
+// what each of their cells is short of to capture its weakest neutral neighbor. Only
+// Segments short of strength are kept, and past reinforceSegments of them the
+// Fronts and then the smallest Demand.
+func FindSegments(cells *Cells, owner int, fronts []*Front, ratio float64) []*Segment {
+	ownedCells, ok := cells.ByOwner[owner]
+	if !ok {
+		return nil
+	}
+	segments := make([]*Segment, 0, len(fronts))
+	atFront := make(map[hlt.Location]bool)
+	for _, front := range fronts {
+		need := int(math.Max(1, ratio) * float64(front.EnemyStrength))
+		segments = append(segments, &Segment{Cells: front.Cells, Front: front, Demand: max(0, need-front.Strength)})
+		for _, cell := range front.Cells {
+			atFront[cell.Location] = true
+		}
+	}
+	seen := make(map[hlt.Location]bool)
+	for _, start := range ownedCells.BorderCells() {
+		if atFront[start.Location] || seen[start.Location] {
+			continue
+		}
+		seen[start.Location] = true
+		segment := &Segment{}
+		queue := []*Cell{start}
+		for len(queue) > 0 && len(segment.Cells) < segmentSize {
+			cell := queue[0]
+			queue = queue[1:]
+			segment.Cells = append(segment.Cells, cell)
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					location := cells.GetSafeLocation(cell.X+dx, cell.Y+dy)
+					neighbor := cells.Get(location.X, location.Y)
+					if neighbor.Owner == owner && neighbor.Border() && !atFront[neighbor.Location] && !seen[neighbor.Location] {
+						seen[neighbor.Location] = true
+						queue = append(queue, neighbor)
+					}
+				}
+			}
+		}
+		// cells found but not taken start Segments of their own
+		for _, cell := range queue {
+			seen[cell.Location] = false
+		}
+		cost := 0
+		for _, cell := range segment.Cells {
+			weakest := -1
+			for _, neighbor := range cell.Neighbors() {
+				if neighbor.Owner == unowned && (weakest < 0 || neighbor.Strength < weakest) {
+					weakest = neighbor.Strength
+				}
+			}
+			cost += max(0, weakest-cell.Strength)
+		}
+		segment.Demand = cost
+		segments = append(segments, segment)
+	}
+	short := make([]*Segment, 0, len(segments))
+	for _, segment := range segments {
+		if segment.Demand > 0 {
+			short = append(short, segment)
+		}
+	}
+	if len(short) > reinforceSegments {
+		sort.SliceStable(short, func(i, j int) bool {
+			if (short[i].Front == nil) != (short[j].Front == nil) {
+				return short[i].Front != nil
+			}
+			return short[i].Front == nil && short[i].Demand < short[j].Demand
+		})
+		short = short[:reinforceSegments]
+	}
+	for _, segment := range short {
+		segment.Field = NewBorderFlow(owner, segment.Cells)
+	}
+	return short
+}
+
+// Reinforcements are the body cells sent to each Segment
+type Reinforcements struct {
+	Segments []*Segment
+	// Sources are the body cells that could be sent, strongest first
+	Sources []*Cell
+	// Sent is the Segment each body cell is sent to, missing for cells kept back
+	Sent map[hlt.Location]*Segment
+	// Flow is the strength sent and Cost what it took, strength times Field cost
+	Flow int
+	Cost int
+}
+
+// Reinforce sends the strength of sources to the Segments' Demand as a min cost
+// flow, paying the cost of each Segment's Field per strength sent. A source
+// split between Segments goes to the one taking most of it.
+func Reinforce(segments []*Segment, sources []*Cell) *Reinforcements {
+	r := &Reinforcements{Segments: segments, Sources: sources, Sent: make(map[hlt.Location]*Segment)}
+	// the source, one node per cell and Segment, then the sink
+	sink := len(sources) + len(segments) + 1
+	graph := NewMinCostFlow(sink + 1)
+	for j, segment := range segments {
+		graph.AddEdge(len(sources)+1+j, sink, segment.Demand, 0)
+	}
+	edges := make([][]int, len(sources))
+	for i, cell := range sources {
+		graph.AddEdge(0, i+1, cell.Strength, 0)
+		edges[i] = make([]int, len(segments))
+		for j, segment := range segments {
+			edges[i][j] = -1
+			if segment.Demand > 0 && segment.Field.Reached(cell.Location) {
+				edges[i][j] = graph.AddEdge(i+1, len(sources)+1+j, cell.Strength, segment.Field.Cost(cell.Location))
+			}
+		}
+	}
+	r.Flow, r.Cost = graph.Solve(0, sink)
+	for i, cell := range sources {
+		most := 0
+		for j, edge := range edges[i] {
+			if edge >= 0 && graph.Flow(edge) > most {
+				most = graph.Flow(edge)
+				r.Sent[cell.Location] = segments[j]
+			}
+		}
+	}
+	return r
+}
+
+// Route is a copy of field where every cell sent to a Segment, and every cell on
+// its way there, follows the Segment's Field. Stronger sources lay their path
+// first, a weaker one joins a path it runs into.
+func (r *Reinforcements) Route(field *FlowField) *FlowField {
+	routed := field.Clone()
+	routing := make(map[hlt.Location]bool)
+	for _, source := range r.Sources {
+		segment, ok := r.Sent[source.Location]
+		if !ok {
+			continue
+		}
+		for cell := source; cell != nil && !routing[cell.Location]; {
+			routing[cell.Location] = true
+			direction := segment.Field.Direction(cell.Location)
+			if direction == hlt.STILL {
+				break
+			}
+			routed.Set(cell.Location, routed.Cost(cell.Location), direction)
+			cell = cell.Cells.GetCell(cell.Location, direction)
+		}
+	}
+	return routed
+}
+
+// reinforcers are the body cells ready to leave, as MoveStrategyBodyFlow
+// waits for them, strongest first and at most reinforceSources of them
+func (b *Bot) reinforcers() []*Cell {
+	wait := b.Params.orDefault().BodyWait
+	sources := make([]*Cell, 0)
+	for _, cell := range b.BodyCells() {
+		if cell.Strength > cell.Production*wait {
+			sources = append(sources, cell)
+		}
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Strength > sources[j].Strength
+	})
+	return sources[:min(len(sources), reinforceSources)]
+}
+
+// MinCostFlow is a flow network sending the most flow it can from a source to a
+// sink for the least cost, by successive shortest paths
+type MinCostFlow struct {
+	// edges by index, edge i^1 is the residual of edge i
+	to       []int
+	capacity []int
+	cost     []int
+	adjacent [][]int
+}
+
+// NewMinCostFlow is a constructor for a network of nodes numbered from 0
+func NewMinCostFlow(nodes int) *MinCostFlow {
+	return &MinCostFlow{adjacent: make([][]int, nodes)}
+}
+
+// AddEdge from one node to another and returns its index
+func (g *MinCostFlow) AddEdge(from int, to int, capacity int, cost int) int {
+	edge := len(g.to)
+	g.to = append(g.to, to, from)
+	g.capacity = append(g.capacity, capacity, 0)
+	g.cost = append(g.cost, cost, -cost)
+	g.adjacent[from] = append(g.adjacent[from], edge)
+	g.adjacent[to] = append(g.adjacent[to], edge+1)
+	return edge
+}
+
+// Flow sent along an edge by Solve
+func (g *MinCostFlow) Flow(edge int) int {
+	return g.capacity[edge^1]
+}
+
+// Solve sends flow along the cheapest path left until the sink cannot be
+// reached, returning the flow sent and its cost
+func (g *MinCostFlow) Solve(source int, sink int) (int, int) {
+	flow, cost := 0, 0
+	nodes := len(g.adjacent)
+	distance := make([]int, nodes)
+	via := make([]int, nodes)
+	queued := make([]bool, nodes)
+	for {
+		for node := range distance {
+			distance[node] = math.MaxInt32
+			via[node] = -1
+		}
+		distance[source] = 0
+		// Bellman-Ford with a queue, residual edges cost less than nothing
+		queue := []int{source}
+		queued[source] = true
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			queued[node] = false
+			for _, edge := range g.adjacent[node] {
+				next := g.to[edge]
+				if g.capacity[edge] > 0 && distance[node]+g.cost[edge] < distance[next] {
+					distance[next] = distance[node] + g.cost[edge]
+					via[next] = edge
+					if !queued[next] {
+						queued[next] = true
+						queue = append(queue, next)
+					}
+				}
+			}
+		}
+		if via[sink] < 0 {
+			return flow, cost
+		}
+		push := math.MaxInt32
+		for node := sink; node != source; node = g.to[via[node]^1] {
+			push = min(push, g.capacity[via[node]])
+		}
+		for node := sink; node != source; node = g.to[via[node]^1] {
+			g.capacity[via[node]] -= push
+			g.capacity[via[node]^1] += push
+		}
+		flow += push
+		cost += push * distance[sink]
+	}
+}
+
+/*
+██████   █████  ██████   █████  ███    ███ ███████
+██   ██ ██   ██ ██   ██ ██   ██ ████  ████ ██
+██████  ███████ ██████  ███████ ██ ████ ██ ███████
+██      ██   ██ ██   ██ ██   ██ ██  ██  ██      ██
+██      ██   ██ ██   ██ ██   ██ ██      ██ ███████
+*/
+
+// Params holds every tunable of the bot. A nil *Params reads as DefaultParams.
+type Params struct {
+	// Weights of production, strength and territory in OwnerScore.SingleScore
+	ProductionWeight float64 `json:"production_weight"`
+	StrengthWeight   float64 `json:"strength_weight"`
+	TerritoryWeight  float64 `json:"territory_weight"`
+	// Each OwnerScore value is divided by its scale before weighting, the
+	// defaults suit a simSize x simSize window
+	ProductionScale float64 `json:"production_scale"`
+	StrengthScale   float64 `json:"strength_scale"`
+	TerritoryScale  float64 `json:"territory_scale"`
+	// Width and height of the window projected around a cell
+	SimSize int `json:"sim_size"`
+	// Body cells wait until their strength is over BodyWait times their production
+	BodyWait int `json:"body_wait"`
+	// Turns looked ahead, states kept per turn and window size of a Search
+	SearchDepth int `json:"search_depth"`
+	SearchWidth int `json:"search_width"`
+	SearchSize  int `json:"search_size"`
+	// Turns ahead the expansion Plan schedules captures, 0 turns it off and
+	// leaves only the merges NewMergePlan coordinates
+	PlanHorizon int `json:"plan_horizon"`
+	// A Front breaks its Wall once our strength there is WallRatio times the
+	// enemy's, 0 never keeps a Wall
+	WallRatio float64 `json:"wall_ratio"`
+}
+
+// DefaultParams is a constructor for the Params the bot plays with unless told otherwise
+func DefaultParams() *Params {
+	return &Params{
+		ProductionWeight: pMod,
+		StrengthWeight:   sMod,
+		TerritoryWeight:  tMod,
+		ProductionScale:  simSize * simSize,
+		StrengthScale:    maxStrength,
+		TerritoryScale:   simSize * simSize,
+		SimSize:          simSize,
+		BodyWait:         bodyWait,
+		SearchDepth:      searchDepth,
+		SearchWidth:      searchWidth,
+		SearchSize:       searchSize,
+		PlanHorizon:      planHorizon,
+		WallRatio:        wallRatio,
+	}
+}
+
+// LoadParams reads Params from a JSON file, fields it leaves out keep their defaults
+func LoadParams(path string) (*Params, error) {
+	p := DefaultParams()
+	if err := p.Load(path); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Load overwrites the fields set in a JSON file
+func (p *Params) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return fmt.Errorf("params %s: %v", path, err)
+	}
+	return p.Validate()
+}
+
+// Save writes the Params to a JSON file
+func (p *Params) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Validate reports Params the bot cannot play with
+func (p *Params) Validate() error {
+	if p.ProductionScale <= 0 || p.StrengthScale <= 0 || p.TerritoryScale <= 0 {
+		return errors.New("params: scales must be positive")
+	}
+	if p.SimSize < 1 || p.SearchSize < 1 || p.SearchDepth < 1 || p.SearchWidth < 1 {
+		return errors.New("params: sizes, depth and width must be at least 1")
+	}
+	if p.BodyWait < 0 || p.PlanHorizon < 0 || p.WallRatio < 0 {
+		return errors.New("params: body_wait, plan_horizon and wall_ratio must not be negative")
+	}
+	return nil
+}
+
+// defaultParams is what a nil *Params stands for, shared so hot paths do not
+// allocate it. It must not be changed.
+var defaultParams = DefaultParams()
+
+// orDefault lets a nil *Params stand for DefaultParams
+func (p *Params) orDefault() *Params {
+	if p == nil {
+		return defaultParams
+	}
+	return p
+}
+
+// paramsFile is the -params flag, loading a JSON file over the Params as soon as
+// it is parsed so that flags after it take precedence
+type paramsFile struct {
+	params *Params
+	path   string
+}
+
+func (f *paramsFile) String() string {
+	return f.path
+}
+
+func (f *paramsFile) Set(path string) error {
+	f.path = path
+	return f.params.Load(path)
+}
+
+// RegisterFlags adds a -params file flag and a flag for every field to flags
+func (p *Params) RegisterFlags(flags *flag.FlagSet) {
+	flags.Var(&paramsFile{params: p}, "params", "JSON file of Params, flags after it override its values")
+	flags.Float64Var(&p.ProductionWeight, "production-weight", p.ProductionWeight, "weight of production in scores")
+	flags.Float64Var(&p.StrengthWeight, "strength-weight", p.StrengthWeight, "weight of strength in scores")
+	flags.Float64Var(&p.TerritoryWeight, "territory-weight", p.TerritoryWeight, "weight of territory in scores")
+	flags.Float64Var(&p.ProductionScale, "production-scale", p.ProductionScale, "production is divided by this in scores")
+	flags.Float64Var(&p.StrengthScale, "strength-scale", p.StrengthScale, "strength is divided by this in scores")
+	flags.Float64Var(&p.TerritoryScale, "territory-scale", p.TerritoryScale, "territory is divided by this in scores")
+	flags.IntVar(&p.SimSize, "sim-size", p.SimSize, "width and height of projected windows")
+	flags.IntVar(&p.BodyWait, "body-wait", p.BodyWait, "body cells move once strength is over this many turns of production")
+	flags.IntVar(&p.SearchDepth, "search-depth", p.SearchDepth, "turns looked ahead by search")
+	flags.IntVar(&p.SearchWidth, "search-width", p.SearchWidth, "states kept per turn by search")
+	flags.IntVar(&p.SearchSize, "search-size", p.SearchSize, "width and height of search windows")
+	flags.IntVar(&p.PlanHorizon, "plan-horizon", p.PlanHorizon, "turns ahead captures are planned, 0 turns the planner off")
+	flags.Float64Var(&p.WallRatio, "wall-ratio", p.WallRatio, "times the enemy's strength we need at a front to break its wall, 0 keeps no walls")
+}
+
+/*
+██████   ██████  ████████
+██   ██ ██    ██    ██
+██████  ██    ██    ██
+██   ██ ██    ██    ██
+██████   ██████     ██
+*/
+
+// TurnTime is how long we allow ourselves per turn, under halite.io's 1s limit
+const TurnTime = 900 * time.Millisecond
+
+// budgetReserve is the time left at which expensive strategies give way to cheap ones
+const budgetReserve = 250 * time.Millisecond
+
+// budgetMargin is the time left at which we stop deciding and leave cells STILL
+const budgetMargin = 50 * time.Millisecond
+
+// Budget is the time left to decide a turn. A nil Budget never runs out.
+type Budget struct {
+	Deadline time.Time
+}
+
+// NewBudget is a constructor for a Budget ending after the given duration
+func NewBudget(duration time.Duration) *Budget {
+	return &Budget{Deadline: time.Now().Add(duration)}
+}
+
+// Remaining is the time left before the deadline
+func (b *Budget) Remaining() time.Duration {
+	if b == nil {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Until(b.Deadline)
+}
+
+// Low is true once only budgetReserve remains, expensive work should be skipped
+func (b *Budget) Low() bool {
+	return b.Remaining() < budgetReserve
+}
+
+// Expired is true once only budgetMargin remains, the frame must be sent now
+func (b *Budget) Expired() bool {
+	return b.Remaining() < budgetMargin
+}
+
+// Bot is in control of Agents for all owned cells.
+type Bot struct {
+	Owner int
+	Cells *Cells
+	// GameMap hlt.GameMap
+	// ToBorder          *FlowField
+	BodyFlow          *FlowField
+	ThreatFlows       map[int]*FlowField
+	ToHighestProd     map[hlt.Location]*FlowField
+	StartingLocations map[int]hlt.Location
+	// Targets are the best production Regions to expand into, best first
+	Targets []*Region
+	// updates counts the frames seen, Targets are ranked again every regionRerank
+	updates int
+	// Fronts of our border against each enemy
+	Fronts []*Front
+	fronts map[hlt.Location]*Front
+	walls  map[hlt.Location]bool
+	// Reinforcements sending the body to the border where it is short
+	Reinforcements *Reinforcements
+	// Strategies used to pick moves for border and body cells, and their names
+	BorderStrategy     Strategy
+	BodyStrategy       Strategy
+	BorderStrategyName string
+	BodyStrategyName   string
+	// Decisions made by the last call to MovesWithin
+	Decisions []Decision
+	// Plan of captures made by the last call to MovesWithin, nil when not planning
+	Plan *Plan
+	// Merges made by the last call to MovesWithin with the planner off, nil otherwise
+	Merges *Plan
+	// Waste left in the moves of the last call to MovesWithin
+	Waste Waste
+	// Opponents learned from every frame seen
+	Opponents *OpponentModel
+	previous  *Cells
+	// Budget for the turn being decided
+	Budget *Budget
+	// Params tuning every strategy
+	Params *Params
+}
+
+// NewBot is a constructor
+func NewBot(owner int, gameMap hlt.GameMap) *Bot {
+	bot := &Bot{
+		Owner: owner,
+		Cells: NewCells(0, 0, gameMap.Width, gameMap.Height, gameMap),
+		// GameMap:           gameMap,
+		BodyFlow:           NewEmptyFlow(),
+		ThreatFlows:        make(map[int]*FlowField),
+		ToHighestProd:      make(map[hlt.Location]*FlowField),
+		StartingLocations:  make(map[int]hlt.Location),
+		BorderStrategy:     Strategies[DefaultBorderStrategy],
+		BodyStrategy:       Strategies[DefaultBodyStrategy],
+		BorderStrategyName: DefaultBorderStrategy,
+		BodyStrategyName:   DefaultBodyStrategy,
+		Params:             DefaultParams(),
+		Opponents:          NewOpponentModel(owner),
+	}
+	// set starting positions for all teams to their center of mass location
+	for team, ownedCells := range bot.Cells.ByOwner {
+		com := ownedCells.CenterOfMass()
+		bot.StartingLocations[team] = bot.Cells.GetSafeLocation(com.X, com.Y)
+	}
+	// generate limited fields for all the highest production cells.
+	highestProdCells := bot.Cells.GetHighestProductionCells()
+	// nearestCell := highestProdCells[0]
+	// nearestDist := gameMap.GetDistance(bot.StartingLocations[owner], nearestCell.Location)
+	// for _, highProdCell := range highestProdCells[1:] {
+	// 	distance := gameMap.GetDistance(bot.StartingLocations[owner], highProdCell.Location)
+	// 	if distance < nearestDist {
+	// 		nearestDist = distance
+	// 		nearestCell = highProdCell
+	// 	}
+	// }
+	// log("Highest Prod:", len(highestProdCells))
+	// bot.ToHighestProd[nearestCell.Location] = NewStrengthFlow(nearestCell)
+	for _, cell := range highestProdCells {
+		bot.ToHighestProd[cell.Location] = NewStrengthFlow(cell)
+		// log(FlowString(2, bot.ToHighestProd[cell.Location], bot.Cells))
+	}
+	// rank clusters of high production by what they cost to reach from our start
+	if start, ok := bot.StartingLocations[owner]; ok {
+		bot.rankTargets([]*Cell{bot.Cells.Get(start.X, start.Y)})
+	}
+	return bot
+}
+
+// rankTargets sets Targets to the best regions still unowned, ranked from origins
+func (b *Bot) rankTargets(origins []*Cell) {
+	regions := RankRegions(b.Cells, FindRegions(b.Cells, RegionThreshold(b.Cells)), origins)
+	b.Targets = regions[:min(len(regions), regionTargets)]
+}
+
+// NewBotFromReplay rebuilds the Bot playing as owner on the given turn of a recorded
+// game, ready to reproduce the Moves it would make.
+func NewBotFromReplay(owner int, r *replay.Replay, turn int) (*Bot, error) {
+	initialMap, err := r.GameMap(0)
+	if err != nil {
+		return nil, err
+	}
+	gameMap, err := r.GameMap(turn)
+	if err != nil {
+		return nil, err
+	}
+	bot := NewBot(owner, initialMap)
+	bot.Update(gameMap)
+	// the frames in between were not seen
+	bot.Opponents = NewOpponentModel(owner)
+	return bot, nil
+}
+
+// Update takes in new map data and updates agents following a turn
+func (b *Bot) Update(gameMap hlt.GameMap) {
+	b.UpdateWithin(gameMap, nil)
+}
+
+// UpdateWithin is Update, leaving the body unreinforced when the budget runs low
+func (b *Bot) UpdateWithin(gameMap hlt.GameMap, budget *Budget) {
+	b.Budget = budget
+	// b.GameMap = gameMap
+	b.Cells.Update(gameMap)
+	// inferring moves is most of an update, done only for a strategy using it
+	if b.previous != nil && b.modelled() {
+		b.Opponents.Observe(b.previous, b.Cells)
+	}
+	// b.ToBorder = NewBorderFlow(b.Owner, b.BorderCells())
+	b.ThreatFlows = ThreatFlows(b.Cells)
+	b.updateFronts()
+	b.previous = b.Cells.Clone()
+	// the best regions change as we and the enemies expand
+	if b.updates++; b.updates%regionRerank == 0 {
+		b.rankTargets(b.OwnedCells())
+	}
+	UpdateRegionFlows(b.Targets)
+	// the body heads for production unless sent to a Segment short of strength
+	b.BodyFlow = NewBodyFlow(b.Owner, b.BorderCells(), b.ToHighestProd)
+	// log(FlowString(2, b.BodyFlow, b.Cells))
+	b.Reinforcements = nil
+	if budget.Low() {
+		return
+	}
+	segments := FindSegments(b.Cells, b.Owner, b.Fronts, b.Params.orDefault().WallRatio)
+	if budget.Low() {
+		return
+	}
+	b.Reinforcements = Reinforce(segments, b.reinforcers())
+	b.BodyFlow = b.Reinforcements.Route(b.BodyFlow)
+}
+
+// OwnedCells returns the Cells owned by this Bot
+func (b *Bot) OwnedCells() []*Cell {
+	return b.Cells.ByOwner[b.Owner].OwnedCells()
+}
+
+// BorderCells returns the Cells owned by this Bot that have a non-owner-owned neighbor
+func (b *Bot) BorderCells() []*Cell {
+	return b.Cells.ByOwner[b.Owner].BorderCells()
+}
+
+// BodyCells returns the Cells owned by this Bot that have only owner-owned neighbors
+func (b *Bot) BodyCells() []*Cell {
+	return b.Cells.ByOwner[b.Owner].BodyCells()
+}
+
+// Moves puts together a list of Moves for each Agent owned within the default TurnTime
+func (b *Bot) Moves() hlt.MoveSet {
+	return b.MovesWithin(NewBudget(TurnTime))
+}
+
+// MovesWithin puts together a list of Moves for each Agent owned, degrading to
+// cheaper strategies as the budget runs low. Cells left once it expires are
+// given no move, which the environment treats as STILL. Moves made in time
+// go through PreventWaste.
+func (b *Bot) MovesWithin(budget *Budget) hlt.MoveSet {
+	b.Budget = budget
+	b.Decisions = b.Decisions[:0]
+	b.Plan = nil
+	b.Merges = nil
+	b.Waste = Waste{}
+	// plan expansion away from engaged Fronts, or with the planner off still
+	// coordinate merges
+	if horizon := b.Params.orDefault().PlanHorizon; !budget.Low() {
+		if horizon > 0 {
+			b.Plan = NewPlan(b, horizon)
+		} else {
+			b.Merges = NewMergePlan(b)
+		}
+	}
+	var moves = hlt.MoveSet{}
+	for _, cell := range b.BorderCells() {
+		if budget.Expired() {
+			return moves
+		}
+		moves = append(moves, b.decide(cell, b.BorderStrategy, b.BorderStrategyName))
+	}
+	for _, cell := range b.BodyCells() {
+		if budget.Expired() {
+			return moves
+		}
+		moves = append(moves, b.decide(cell, b.BodyStrategy, b.BodyStrategyName))
+	}
+	checked, waste := b.PreventWaste(moves)
+	for i, move := range checked {
+		if move != moves[i] {
+			b.Decisions[i] = Decision{Move: move, Strategy: capStrategy}
+		}
+	}
+	b.Waste = waste
+	return checked
+}
+
+// Decision is a move and the name of the strategy that made it
+type Decision struct {
+	Move     hlt.Move
+	Strategy string
+}
+
+// decide moves a cell as the Plan orders, else with the strategy or the fallback
+// when the budget is low, and keeps the Decision
+func (b *Bot) decide(cell *Cell, strategy Strategy, name string) hlt.Move {
+	if direction, ok := b.Plan.Order(cell.Location); ok {
+		move := hlt.Move{Location: cell.Location, Direction: direction}
+		b.Decisions = append(b.Decisions, Decision{Move: move, Strategy: planStrategy})
+		return move
+	}
+	if direction, ok := b.Merges.Order(cell.Location); ok {
+		move := hlt.Move{Location: cell.Location, Direction: direction}
+		b.Decisions = append(b.Decisions, Decision{Move: move, Strategy: mergeStrategy})
+		return move
+	}
+	if b.Budget.Low() {
+		name = fallbackStrategy
+	}
+	strategy = b.StrategyWithinBudget(strategy)
+	// a Decider may hand the cell on to another strategy and names the one that moved it
+	decision := Decision{Strategy: name}
+	if decider, ok := strategy.(Decider); ok {
+		decision = decider.Decide(b, cell)
+	} else {
+		decision.Move = strategy.Move(b, cell)
+	}
+	b.Decisions = append(b.Decisions, decision)
+	return decision.Move
+}
+
+// StrategyWithinBudget swaps the strategy for the cheap fallbackStrategy once the budget is low
+func (b *Bot) StrategyWithinBudget(strategy Strategy) Strategy {
+	if b.Budget.Low() {
+		return Strategies[fallbackStrategy]
+	}
+	return strategy
+}
+
+// Strategy picks the Move for a single owned cell
+type Strategy interface {
+	Move(b *Bot, cell *Cell) hlt.Move
+}
+
+// StrategyFunc allows a function, or a Bot method expression, to be used as a Strategy
+type StrategyFunc func(b *Bot, cell *Cell) hlt.Move
+
+// Move calls f
+func (f StrategyFunc) Move(b *Bot, cell *Cell) hlt.Move {
+	return f(b, cell)
+}
+
+// Decider is a Strategy that hands some cells on to other strategies, its
+// Decision names the one that moved the cell
+type Decider interface {
+	Strategy
+	Decide(b *Bot, cell *Cell) Decision
+}
+
+// DecisionFunc allows a function, or a Bot method expression, returning a
+// Decision to be used as a Decider
+type DecisionFunc func(b *Bot, cell *Cell) Decision
+
+// Move is the Move of the Decision f makes
+func (f DecisionFunc) Move(b *Bot, cell *Cell) hlt.Move {
+	return f(b, cell).Move
+}
+
+// Decide calls f
+func (f DecisionFunc) Decide(b *Bot, cell *Cell) Decision {
+	return f(b, cell)
+}
+
+// DefaultBorderStrategy and DefaultBodyStrategy are the Strategies a NewBot plays
+const DefaultBorderStrategy = "engaged"
+const DefaultBodyStrategy = "flow"
+const fallbackStrategy = "v5"
+
+// Strategies is the registry of named strategies a Bot can be composed from
+var Strategies = map[string]Strategy{
+	"engaged":    DecisionFunc((*Bot).MoveStrategyEngaged),
+	"profit":     DecisionFunc((*Bot).MoveStrategyProfit),
+	"v5":         StrategyFunc((*Bot).MoveStrategyV5),
+	"overkill":   StrategyFunc((*Bot).MoveStrategyOverkill),
+	"combat":     DecisionFunc((*Bot).MoveStrategyCombat),
+	"projection": DecisionFunc((*Bot).MoveStrategyProjection),
+	"search":     DecisionFunc((*Bot).MoveStrategySearch),
+	"flow":       StrategyFunc((*Bot).MoveStrategyBodyFlow),
+	"still":      StrategyFunc((*Bot).MoveStrategyStill),
+}
+
+// ModelStrategies are the names of the strategies reading Bot.Opponents. A Bot
+// only observes its opponents while its border or body strategy is one of them.
+var ModelStrategies = map[string]bool{"projection": true}
+
+// RegisterStrategy adds or replaces a named Strategy
+func RegisterStrategy(name string, strategy Strategy) {
+	Strategies[name] = strategy
+}
+
+// StrategyNames is the sorted list of registered Strategy names
+func StrategyNames() []string {
+	names := make([]string, 0, len(Strategies))
+	for name := range Strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// modelled is whether a strategy in use reads the OpponentModel
+func (b *Bot) modelled() bool {
+	return ModelStrategies[b.BorderStrategyName] || ModelStrategies[b.BodyStrategyName]
+}
+
+// UseStrategies sets the border and body strategies by name
+func (b *Bot) UseStrategies(border string, body string) error {
+	borderStrategy, ok := Strategies[border]
+	if !ok {
+		return fmt.Errorf("unknown border strategy %q, have %v", border, StrategyNames())
+	}
+	bodyStrategy, ok := Strategies[body]
+	if !ok {
+		return fmt.Errorf("unknown body strategy %q, have %v", body, StrategyNames())
+	}
+	b.BorderStrategy = borderStrategy
+	b.BodyStrategy = bodyStrategy
+	b.BorderStrategyName = border
+	b.BodyStrategyName = body
+	return nil
+}
+
+// MoveStrategyEngaged trades blows with MoveStrategyV5 at a hot Front, going for
+// overkill, and expands with MoveStrategyProfit at quiet ones and elsewhere
+func (b *Bot) MoveStrategyEngaged(cell *Cell) Decision {
+	if b.Engaged(cell) {
+		return Decision{Move: b.MoveStrategyV5(cell), Strategy: "v5"}
+	}
+	return b.MoveStrategyProfit(cell)
+}
+
+// MoveStrategyBodyFlow waits until a cell has built up Params.BodyWait turns of
+// production, then follows BodyFlow towards the border
+func (b *Bot) MoveStrategyBodyFlow(cell *Cell) hlt.Move {
+	if cell.Strength > cell.Production*b.Params.orDefault().BodyWait {
+		return hlt.Move{Location: cell.Location, Direction: b.BodyFlow.Direction(cell.Location)}
+	}
+	return hlt.Move{Location: cell.Location, Direction: hlt.STILL}
+}
+
+// MoveStrategyStill never moves
+func (b *Bot) MoveStrategyStill(cell *Cell) hlt.Move {
+	return hlt.Move{Location: cell.Location, Direction: hlt.STILL}
+}
+
+// MoveStrategyProfit heads for the target Region giving the most production per
+// strength spent from this cell, counting the strength still to capture there.
+// Cells whose way there crosses our own territory or a Wall expand with
+// MoveStrategyV5.
+func (b *Bot) MoveStrategyProfit(cell *Cell) Decision {
+	var target *Region
+	targetValue := 0.0
+	for _, region := range b.Targets {
+		production, strength := region.Remaining()
+		if production == 0 || !region.Flow.Reached(cell.Location) {
+			continue
+		}
+		value := float64(production) / float64(1+region.Flow.Cost(cell.Location)+strength)
+		if target == nil || value > targetValue {
+			target = region
+			targetValue = value
+		}
+	}
+	if target != nil {
+		direction := target.Flow.Direction(cell.Location)
+		// border cells facing the target take it, the rest of the border expands
+		if destination := b.Cells.GetCell(cell.Location, direction); direction != hlt.STILL && destination != nil && destination.Owner != b.Owner && !b.Walled(destination) {
+			if cell.Strength > destination.Strength {
+				return Decision{Move: hlt.Move{Location: cell.Location, Direction: direction}, Strategy: "profit"}
+			}
+			return Decision{Move: hlt.Move{Location: cell.Location, Direction: hlt.STILL}, Strategy: "profit"}
+		}
+	}
+	return Decision{Move: b.MoveStrategyV5(cell), Strategy: "v5"}
+}
+
+// MoveStrategyV5 takes the neighbor with the best Heuristic once the cell is
+// stronger than it, leaving Walls be
+func (b *Bot) MoveStrategyV5(cell *Cell) hlt.Move {
+	targetDirection := hlt.STILL
+	var targetCell *Cell
+	targetHeuristic := 0.0
+	for dir, neighbor := range cell.Neighbors() {
+		direction := hlt.Direction(dir + 1)
+		if neighbor.Owner != b.Owner && !b.Walled(neighbor) {
+			otherHeuristic := neighbor.Heuristic(b.Owner)
+			if targetCell == nil || otherHeuristic > targetHeuristic {
+				targetCell = neighbor
+				targetDirection = direction
+				targetHeuristic = otherHeuristic
+			}
+		}
+	}
+	if targetCell != nil && targetCell.Strength < cell.Strength {
+		return hlt.Move{Location: cell.Location, Direction: targetDirection}
+	}
+	return hlt.Move{Location: cell.Location, Direction: hlt.STILL}
+}
+
+func (b *Bot) MoveStrategyOverkill(cell *Cell) hlt.Move {
+	maxOverkillDirection := hlt.STILL
+	maxOverkill := 0
+	for _, direction := range hlt.Directions {
+		otherCell := b.Cells.GetCell(cell.Location, direction)
+		overkill := otherCell.Overkill(b.Owner, cell.Strength)
+		if overkill > maxOverkill {
+			maxOverkillDirection = direction
+			maxOverkill = overkill
+		}
+	}
+	return hlt.Move{
+		Location:  cell.Location,
+		Direction: maxOverkillDirection,
+	}
+}
+
+// MoveStrategyProjection is an Expensive movement strategy where board state
+// is projected for all possible moves around a cell, from which we pick the best.
+func (b *Bot) MoveStrategyProjection(cell *Cell) Decision {
+	if b.Budget.Low() {
+		return Decision{Move: b.MoveStrategyV5(cell), Strategy: "v5"}
+	}
+	move := hlt.Move{
+		Location:  cell.Location,
+		Direction: b.BestMoveFromProjection(cell.Location, b.Budget),
+	}
+	return Decision{Move: move, Strategy: "projection"}
+}
+
+// MoveStrategySearch looks searchDepth turns ahead for cells an enemy threatens,
+// plays MoveStrategyEngaged elsewhere and falls back on MoveStrategyV5 when the
+// budget is low
+func (b *Bot) MoveStrategySearch(cell *Cell) Decision {
+	if b.Budget.Low() {
+		return Decision{Move: b.MoveStrategyV5(cell), Strategy: "v5"}
+	}
+	for team, flow := range b.ThreatFlows {
+		if team != unowned && team != b.Owner && flow.Cost(cell.Location) > 0 {
+			size := b.Params.orDefault().SearchSize
+			cells := NewCells(cell.X-size/2, cell.Y-size/2, size, size, b.Cells.GameMap)
+			move := hlt.Move{
+				Location:  cell.Location,
+				Direction: NewSearch(b.Owner, b.Params, b.Budget).BestMove(cells, cell.Location),
+			}
+			return Decision{Move: move, Strategy: "search"}
+		}
+	}
+	return b.MoveStrategyEngaged(cell)
+}
+
+// simSize is the default Params.SimSize
+const simSize = 5
+
+// bodyWait is the default Params.BodyWait
+const bodyWait = 5
+
+// ProjectedCells is a small cell space for simulating moves
+func (b *Bot) ProjectedCells(location hlt.Location) *Cells {
+	size := b.Params.orDefault().SimSize
+	return NewCells(location.X-size/2, location.Y-size/2, size, size, b.Cells.GameMap)
+}
+
+// ProjectedMoves is the list of locations that will need moves in order to fully simulate Cells
+func (b *Bot) ProjectedMoves(excluded hlt.Location, cells *Cells) []hlt.Location {
+	owner := cells.Get(excluded.X, excluded.Y).Owner
+	cellsNeedMoves := cells.GetCells(func(cell *Cell) bool {
+		return cell.Owner != unowned && cell.Owner != owner
+	})
+	movesNeeded := make([]hlt.Location, 0, len(cellsNeedMoves))
+	for _, cell := range cellsNeedMoves {
+		if cell.Location != excluded {
+			movesNeeded = append(movesNeeded, cell.Location)
+		}
+	}
+	return movesNeeded
+}
+
+// BestMoveFromProjection projects all possible moves for each cell in a [SimSize x SimSize] copy around the
+// given location. Returning the move that yields the highest score for the location owner, or the best
+// found so far when the budget expires. Once opponents have been observed their moves are drawn from
+// the OpponentModel and scores are expected ones.
+func (b *Bot) BestMoveFromProjection(location hlt.Location, budget *Budget) hlt.Direction {
+	cells := b.ProjectedCells(location)
+	movesNeeded := b.ProjectedMoves(location, cells)
+	owner := cells.Get(location.X, location.Y).Owner
+	maxDirection := hlt.STILL
+	maxSingleScore := 0.0
+	for _, direction := range hlt.Directions {
+		if cells.InBounds(cells.GetLocation(location, direction)) {
+			prevOwnerScore := NewOwnerScore(cells.ByOwner[owner])
+			moves := hlt.MoveSet{hlt.Move{Location: location, Direction: direction}}
+			var singleScore float64
+			if b.Opponents != nil && b.Opponents.Observations > 0 {
+				expected := ProjectExpected(cells, moves, movesNeeded, b.Opponents, b.Params, budget)
+				singleScore = expected[owner] - prevOwnerScore.SingleScore(b.Params)
+			} else {
+				scores := Project(cells, moves, movesNeeded, 0, b.Params, budget)
+				deltaScore := NewDeltaScore(prevOwnerScore, scores[owner])
+				singleScore = deltaScore.SingleScore(b.Params)
+			}
+			// log(DirectionString(direction), ScoreString(deltaScore))
+			if singleScore > maxSingleScore {
+				maxDirection = direction
+				maxSingleScore = singleScore
+			}
+		}
+	}
+	return maxDirection
+}
+
+// Project by simulating cells with picked moves, or if locations still need moves
+// pick the best move for the location owner. Once the budget expires locations
+// still needing moves are left STILL.
+func Project(cells *Cells, moves hlt.MoveSet, movesNeeded []hlt.Location, depth int, params *Params, budget *Budget) map[int]OwnerScore {
+	if len(movesNeeded) == 0 || budget.Expired() {
+		// all moves made, simulate board and return scores
+		newCells := cells.Simulate(moves)
+		scores := make(map[int]OwnerScore)
+		for owner, ownerCells := range newCells.ByOwner {
+			scores[owner] = NewOwnerScore(ownerCells)
+		}
+		return scores
+	}
+	location := movesNeeded[0]
+	owner := cells.Get(location.X, location.Y).Owner
+	var maxScores map[int]OwnerScore
+	maxScore := 0.0
+	for _, direction := range hlt.Directions {
+		if cells.InBounds(cells.GetLocation(location, direction)) {
+			prevOwnerScore := NewOwnerScore(cells.ByOwner[owner])
+			moves = append(moves, hlt.Move{Location: location, Direction: direction})
+			scores := Project(cells, moves, movesNeeded[1:], depth+1, params, budget)
+			singleScore := NewDeltaScore(prevOwnerScore, scores[owner]).SingleScore(params)
+			if singleScore > maxScore {
+				maxScores = scores
+				maxScore = singleScore
+			}
+		}
+	}
+	return maxScores
+}
+
+/*
+███    ███  ██████  ██████  ███████ ██
+████  ████ ██    ██ ██   ██ ██      ██
+██ ████ ██ ██    ██ ██   ██ █████   ██
+██  ██  ██ ██    ██ ██   ██ ██      ██
+██      ██  ██████  ██████  ███████ ███████
+*/
+
+// tendencyBuckets splits observed cells by the turns of production they hold,
+// the last bucket taking everything above
+const tendencyBuckets = 16
+
+// minMoveChance is the least likely opponent move ProjectExpected still explores
+const minMoveChance = 0.05
+
+// moveKind is what a move goes into, seen from the player moving
+type moveKind int
+
+const (
+	kindOwn moveKind = iota
+	kindNeutral
+	kindEnemy
+	moveKinds
+)
+
+// kindOf is the kind of a move by owner into destination
+func kindOf(owner int, destination *Cell) moveKind {
+	switch destination.Owner {
+	case owner:
+		return kindOwn
+	case unowned:
+		return kindNeutral
+	}
+	return kindEnemy
+}
+
+// Tendency counts how a player moved its cells: whether it moved or held by the
+// turns of production a cell held, and what it moved into
+type Tendency struct {
+	Moved [tendencyBuckets]int
+	Held  [tendencyBuckets]int
+	Kinds [moveKinds]int
+}
+
+// bucket is the turns of production a cell holds, up to the last bucket
+func bucket(cell *Cell) int {
+	return min(tendencyBuckets-1, cell.Strength/max(1, cell.Production))
+}
+
+// Observe counts one move of a cell into destination
+func (t *Tendency) Observe(cell *Cell, direction hlt.Direction, destination *Cell) {
+	if direction == hlt.STILL {
+		t.Held[bucket(cell)]++
+		return
+	}
+	t.Moved[bucket(cell)]++
+	t.Kinds[kindOf(cell.Owner, destination)]++
+}
+
+// MoveChance is how likely the player moves the cell rather than hold it,
+// starting from even odds
+func (t *Tendency) MoveChance(cell *Cell) float64 {
+	b := bucket(cell)
+	return float64(t.Moved[b]+1) / float64(t.Moved[b]+t.Held[b]+2)
+}
+
+// Distribution is the chance of each direction the cell can move in within cells:
+// MoveChance shared between the neighbors by how often the player moves into
+// their kind of cell, and the rest on STILL
+func (t *Tendency) Distribution(cells *Cells, cell *Cell) map[hlt.Direction]float64 {
+	weights := make(map[hlt.Direction]float64, 4)
+	total := 0.0
+	for _, direction := range hlt.CARDINALS {
+		if destination := cells.GetCell(cell.Location, direction); destination != nil {
+			weights[direction] = float64(t.Kinds[kindOf(cell.Owner, destination)] + 1)
+			total += weights[direction]
+		}
+	}
+	move := t.MoveChance(cell)
+	distribution := map[hlt.Direction]float64{hlt.STILL: 1}
+	if total == 0 {
+		return distribution
+	}
+	distribution[hlt.STILL] = 1 - move
+	for direction, weight := range weights {
+		distribution[direction] = move * weight / total
+	}
+	return distribution
+}
+
+// OpponentModel learns the Tendency of every player but Owner from the frames
+// seen so far
+type OpponentModel struct {
+	Owner   int
+	Players map[int]*Tendency
+	// Observations is the number of moves counted
+	Observations int
+}
+
+// NewOpponentModel is a constructor
+func NewOpponentModel(owner int) *OpponentModel {
+	return &OpponentModel{Owner: owner, Players: make(map[int]*Tendency)}
+}
+
+// Tendency of a player, empty until it has been observed
+func (m *OpponentModel) Tendency(player int) *Tendency {
+	if _, ok := m.Players[player]; !ok {
+		m.Players[player] = &Tendency{}
+	}
+	return m.Players[player]
+}
+
+// Observe counts the moves every opponent made between two consecutive frames.
+// Moves InferMoves cannot tell from others are not counted.
+func (m *OpponentModel) Observe(prev *Cells, next *Cells) {
+	inference := InferMoves(prev, next)
+	for player, moves := range inference.Moves {
+		if player == m.Owner {
+			continue
+		}
+		tendency := m.Tendency(player)
+		for _, move := range moves {
+			if _, ambiguous := inference.Candidates[move.Location]; ambiguous {
+				continue
+			}
+			cell := prev.Get(move.Location.X, move.Location.Y)
+			tendency.Observe(cell, move.Direction, prev.GetCell(move.Location, move.Direction))
+			m.Observations++
+		}
+	}
+}
+
+// Distribution is the chance of each move of an opponent's cell
+func (m *OpponentModel) Distribution(cells *Cells, cell *Cell) map[hlt.Direction]float64 {
+	return m.Tendency(cell.Owner).Distribution(cells, cell)
+}
+
+// GuessMoves reads the moves owner most likely made between two frames. A cell
+// that kept at least its strength plus production held, one that lost it moved
+// to the neighbor owner gained closest to its strength in. Moves that cannot be
+// told, like a cell with no owned neighbor left, are left out. Fighting makes
+// some guesses wrong, InferMoves starts from them to find the right ones.
+func GuessMoves(prev *Cells, next *Cells, owner int) hlt.MoveSet {
+	moves := hlt.MoveSet{}
+	ownedCells, ok := prev.ByOwner[owner]
+	if !ok {
+		return moves
+	}
+	for _, cell := range ownedCells.OwnedCells() {
+		after := next.Get(cell.X, cell.Y)
+		if after == nil {
+			continue
+		}
+		if after.Owner == owner && after.Strength >= min(maxStrength, cell.Strength+cell.Production) {
+			moves = append(moves, hlt.Move{Location: cell.Location, Direction: hlt.STILL})
+			continue
+		}
+		guess, guessError := hlt.STILL, maxCost
+		for _, direction := range hlt.CARDINALS {
+			before, gained := prev.GetCell(cell.Location, direction), next.GetCell(cell.Location, direction)
+			if before == nil || gained == nil || gained.Owner != owner {
+				continue
+			}
+			gain := gained.Strength
+			if before.Owner == owner {
+				gain -= before.Strength
+			}
+			if e := abs(gain - cell.Strength); e < guessError {
+				guess, guessError = direction, e
+			}
+		}
+		if guess != hlt.STILL && cell.Strength > 0 {
+			moves = append(moves, hlt.Move{Location: cell.Location, Direction: guess})
+		}
+	}
+	return moves
+}
+
+// ProjectExpected is Project with the moves of opponents drawn from the model
+// instead of picked to suit them. It returns the SingleScore each owner can
+// expect, weighing every explored move by its chance. Moves less likely than
+// minMoveChance are not explored.
+func ProjectExpected(cells *Cells, moves hlt.MoveSet, movesNeeded []hlt.Location, model *OpponentModel, params *Params, budget *Budget) map[int]float64 {
+	expected := make(map[int]float64)
+	if len(movesNeeded) == 0 || budget.Expired() {
+		for owner, ownerCells := range cells.Simulate(moves).ByOwner {
+			expected[owner] = NewOwnerScore(ownerCells).SingleScore(params)
+		}
+		return expected
+	}
+	location := movesNeeded[0]
+	distribution := model.Distribution(cells, cells.Get(location.X, location.Y))
+	total := 0.0
+	for _, direction := range hlt.Directions {
+		chance := distribution[direction]
+		if chance < minMoveChance {
+			continue
+		}
+		total += chance
+		next := append(moves[:len(moves):len(moves)], hlt.Move{Location: location, Direction: direction})
+		for owner, score := range ProjectExpected(cells, next, movesNeeded[1:], model, params, budget) {
+			expected[owner] += chance * score
+		}
+	}
+	for owner := range expected {
+		expected[owner] /= total
+	}
+	return expected
+}
+
+/*
+██ ███    ██ ███████ ███████ ██████
+██ ████   ██ ██      ██      ██   ██
+██ ██ ██  ██ █████   █████   ██████
+██ ██  ██ ██ ██      ██      ██   ██
+██ ██   ████ ██      ███████ ██   ██
+*/
+
+// inferReach is how far from a cell a change to its move shows in the next
+// frame: its piece lands next to it and fights the sites next to that
+const inferReach = 2
+
+// inferPasses bounds the rounds of changing one move at a time in InferMoves
+const inferPasses = 3
+
+// Inference is the moves every player most likely made between two frames
+type Inference struct {
+	// Moves of every owned cell that best explain the next frame, by player
+	Moves map[int]hlt.MoveSet
+	// Candidates are the moves explaining the next frame equally well for the
+	// cells where more than one does, the move in Moves first
+	Candidates map[hlt.Location][]hlt.Direction
+	// Error is how far Resolve of Moves is from the next frame, 0 when they explain it
+	Error int
+}
+
+// InferMoves reconstructs the moves of every player from two consecutive frames.
+// It starts from GuessMoves and the pairs of cells seen trading places, then
+// changes the move of one cell, or of two trading places, at a time where the
+// guess is in doubt, keeping the move whose Resolve best matches the next frame
+// around the cell. Cells near a site the guesses get wrong are in doubt,
+// as are those whose move may not show, like the cells of a fight or a merge.
+func InferMoves(prev *Cells, next *Cells) *Inference {
+	// directions of the cells of prev, by Index
+	directions := make([]hlt.Direction, len(prev.Contents))
+	for owner := range prev.ByOwner {
+		if owner != unowned {
+			for _, move := range GuessMoves(prev, next, owner) {
+				directions[prev.Index(move.Location.X, move.Location.Y)] = move.Direction
+			}
+		}
+	}
+	// two cells left holding each other's strength traded places, which no
+	// single move explains
+	for i := range prev.Contents {
+		cell := &prev.Contents[i]
+		if cell.Owner == unowned || next.Contents[i].Strength == min(maxStrength, cell.Strength+cell.Production) {
+			continue
+		}
+		for _, direction := range hlt.CARDINALS {
+			other := prev.GetCell(cell.Location, direction)
+			j := prev.Index(other.X, other.Y)
+			if j >= 0 && other.Owner == cell.Owner && next.Contents[i].Strength == other.Strength && next.Contents[j].Strength == cell.Strength {
+				directions[i], directions[j] = direction, opposite(direction)
+				break
+			}
+		}
+	}
+	// cells whose move may not show: lost in a fight, moving nothing or merging
+	// over the cap
+	doubtful := make([]bool, len(prev.Contents))
+	for i := range prev.Contents {
+		cell := &prev.Contents[i]
+		if cell.Owner == unowned {
+			continue
+		}
+		moved := directions[i] != hlt.STILL
+		switch {
+		case next.Get(cell.X, cell.Y).Owner != cell.Owner:
+			doubtful[i] = true
+		case cell.Strength == 0 && (moved || cell.Production == 0):
+			doubtful[i] = true
+		case moved && next.GetCell(cell.Location, directions[i]).Strength == maxStrength:
+			doubtful[i] = true
+		}
+	}
+	resolved := prev.Resolve(inferredMoves(prev, directions))
+	for i := range resolved.Contents {
+		cell := &resolved.Contents[i]
+		if siteError(cell.Owner, cell.Strength, next.Get(cell.X, cell.Y)) == 0 {
+			continue
+		}
+		// a piece lands next to its cell, and only fights further away
+		reach := 1
+		if fighting(prev, cell.Location) {
+			reach = inferReach
+		}
+		for _, location := range around(prev.GameMap, cell.Location, reach) {
+			if j := prev.Index(location.X, location.Y); j >= 0 && prev.Contents[j].Owner != unowned {
+				doubtful[j] = true
+			}
+		}
+	}
+
+	// cells guessed to move go first, a wrong guess misleads the cells around it
+	order := make([]int, 0, len(doubtful))
+	for _, moved := range []bool{true, false} {
+		for i := range doubtful {
+			if doubtful[i] && (directions[i] != hlt.STILL) == moved {
+				order = append(order, i)
+			}
+		}
+	}
+	// cells are tried again only once a move that can change their score has
+	pending := append([]bool{}, doubtful...)
+	neighborhoods := make(map[int]*neighborhood)
+	candidates := make(map[int][]hlt.Direction)
+	for pass := 0; pass < inferPasses; pass++ {
+		changed := false
+		for _, i := range order {
+			if !pending[i] {
+				continue
+			}
+			pending[i] = false
+			if _, ok := neighborhoods[i]; !ok {
+				neighborhoods[i] = newNeighborhood(prev, next, prev.Contents[i].Location)
+			}
+			n := neighborhoods[i]
+			current := directions[i]
+			best, bestError := []hlt.Direction{current}, n.Error(directions)
+			for _, direction := range hlt.Directions {
+				if direction == current {
+					continue
+				}
+				directions[i] = direction
+				if e := n.Error(directions); e < bestError {
+					best, bestError = []hlt.Direction{direction}, e
+				} else if e == bestError {
+					best = append(best, direction)
+				}
+			}
+			// two cells trading places only shows once both of them move
+			partner := -1
+			for _, direction := range hlt.CARDINALS {
+				if bestError == 0 {
+					break
+				}
+				other := prev.GetCell(prev.Contents[i].Location, direction)
+				j := prev.Index(other.X, other.Y)
+				if j < 0 || other.Owner != prev.Contents[i].Owner || current != hlt.STILL || directions[j] != hlt.STILL {
+					continue
+				}
+				held := directions[j]
+				directions[i], directions[j] = direction, opposite(direction)
+				if e := n.Error(directions); e < bestError {
+					best, bestError, partner = []hlt.Direction{direction}, e, j
+				}
+				directions[j] = held
+			}
+			directions[i] = best[0]
+			candidates[i] = best
+			if partner >= 0 {
+				directions[partner] = opposite(best[0])
+				candidates[partner] = []hlt.Direction{directions[partner]}
+			}
+			if best[0] != current {
+				changed = true
+				for _, location := range around(prev.GameMap, prev.Contents[i].Location, 2*inferReach) {
+					if j := prev.Index(location.X, location.Y); j >= 0 && doubtful[j] && j != i {
+						pending[j] = true
+					}
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	inference := &Inference{Moves: make(map[int]hlt.MoveSet), Candidates: make(map[hlt.Location][]hlt.Direction)}
+	for i := range prev.Contents {
+		cell := &prev.Contents[i]
+		if cell.Owner != unowned {
+			inference.Moves[cell.Owner] = append(inference.Moves[cell.Owner], hlt.Move{Location: cell.Location, Direction: directions[i]})
+		}
+		if len(candidates[i]) > 1 {
+			inference.Candidates[cell.Location] = candidates[i]
+		}
+	}
+	prev.Resolve(inference.Moves).ForEach(func(cell *Cell) {
+		inference.Error += siteError(cell.Owner, cell.Strength, next.Get(cell.X, cell.Y))
+	})
+	return inference
+}
+
+// MoveSets are up to limit candidate MoveSets of owner, the likeliest first and
+// then those changing the move of one ambiguous cell
+func (inf *Inference) MoveSets(owner int, limit int) []hlt.MoveSet {
+	best := inf.Moves[owner]
+	moveSets := []hlt.MoveSet{best}
+	for i, move := range best {
+		for _, direction := range inf.Candidates[move.Location] {
+			if len(moveSets) >= limit {
+				return moveSets
+			}
+			if direction == move.Direction {
+				continue
+			}
+			moveSet := append(hlt.MoveSet{}, best...)
+			moveSet[i].Direction = direction
+			moveSets = append(moveSets, moveSet)
+		}
+	}
+	return moveSets[:min(len(moveSets), limit)]
+}
+
+// inferredMoves groups the directions of the owned cells by player
+func inferredMoves(cells *Cells, directions []hlt.Direction) map[int]hlt.MoveSet {
+	moves := make(map[int]hlt.MoveSet)
+	for i := range cells.Contents {
+		if cell := &cells.Contents[i]; cell.Owner != unowned && directions[i] != hlt.STILL {
+			moves[cell.Owner] = append(moves[cell.Owner], hlt.Move{Location: cell.Location, Direction: directions[i]})
+		}
+	}
+	return moves
+}
+
+// neighborhood holds the sites within inferReach of a location and the cells
+// whose pieces can reach them, as Index of the Cells, so the moves tried there
+// are quick to score
+type neighborhood struct {
+	// sources are the cells, with the site each direction takes them to
+	sources []int
+	reached [][5]int
+	// sites, with the sites a piece on them fights: their own and those next to them
+	sites []int
+	near  [][5]int
+	cells *Cells
+	next  []*Cell
+}
+
+// newNeighborhood is a constructor
+func newNeighborhood(prev *Cells, next *Cells, location hlt.Location) *neighborhood {
+	n := &neighborhood{cells: prev}
+	sitesAround := func(location hlt.Location) [5]int {
+		var sites [5]int
+		for _, direction := range hlt.Directions {
+			other := prev.GetLocation(location, direction)
+			sites[direction] = prev.Index(other.X, other.Y)
+		}
+		return sites
+	}
+	for _, source := range around(prev.GameMap, location, 2*inferReach) {
+		if i := prev.Index(source.X, source.Y); i >= 0 && prev.Contents[i].Owner != unowned {
+			n.sources = append(n.sources, i)
+			n.reached = append(n.reached, sitesAround(source))
+		}
+	}
+	for _, site := range around(prev.GameMap, location, inferReach) {
+		if i, after := prev.Index(site.X, site.Y), next.Get(site.X, site.Y); i >= 0 && after != nil {
+			n.sites = append(n.sites, i)
+			n.near = append(n.near, sitesAround(site))
+			n.next = append(n.next, after)
+		}
+	}
+	return n
+}
+
+// piece is the strength an owner brings to a site
+type piece struct {
+	site     int
+	owner    int
+	strength int
+}
+
+// Error is how far the sites of the neighborhood end up from the next frame
+// when the cells move in directions. It follows the rules of Resolve, only for
+// the pieces that can reach the sites and keeping them in a slice, as it runs
+// for every move tried.
+func (n *neighborhood) Error(directions []hlt.Direction) int {
+	pieces := make([]piece, 0, 2*len(n.sources))
+	add := func(site int, owner int, strength int) {
+		for i := range pieces {
+			if pieces[i].site == site && pieces[i].owner == owner {
+				pieces[i].strength = min(maxStrength, pieces[i].strength+strength)
+				return
+			}
+		}
+		pieces = append(pieces, piece{site: site, owner: owner, strength: min(maxStrength, strength)})
+	}
+	for s, i := range n.sources {
+		cell := &n.cells.Contents[i]
+		destination := n.reached[s][directions[i]]
+		if directions[i] == hlt.STILL || destination < 0 {
+			add(i, cell.Owner, cell.Strength+cell.Production)
+			continue
+		}
+		add(destination, cell.Owner, cell.Strength)
+		add(i, cell.Owner, 0)
+	}
+	e := 0
+	for s, i := range n.sites {
+		near := n.near[s]
+		neutral, neutralDamage := 0, 0
+		if before := &n.cells.Contents[i]; before.Owner == unowned {
+			neutral = before.Strength
+		}
+		owner, strength := unowned, 0
+		for _, p := range pieces {
+			if p.site != i {
+				continue
+			}
+			damage, fought := 0, neutral > 0
+			for _, other := range pieces {
+				if other.owner != p.owner && (other.site == near[0] || other.site == near[1] || other.site == near[2] || other.site == near[3] || other.site == near[4]) {
+					damage += other.strength
+					fought = true
+				}
+			}
+			if neutral > 0 {
+				damage += neutral
+				neutralDamage += p.strength
+			}
+			// pieces taking damage as big as their strength are destroyed, 0 against 0 too
+			if !fought || damage < p.strength {
+				owner, strength = p.owner, p.strength-damage
+			}
+		}
+		if owner == unowned {
+			strength = max(0, neutral-neutralDamage)
+		}
+		e += siteError(owner, strength, n.next[s])
+	}
+	return e
+}
+
+// fighting is true when cells of more than one player are within inferReach of
+// location, whose pieces may fight there
+func fighting(cells *Cells, location hlt.Location) bool {
+	owner := unowned
+	for _, other := range around(cells.GameMap, location, inferReach) {
+		if cell := cells.Get(other.X, other.Y); cell != nil && cell.Owner != unowned {
+			if owner != unowned && cell.Owner != owner {
+				return true
+			}
+			owner = cell.Owner
+		}
+	}
+	return false
+}
+
+// around are the map locations no further than reach from location, each once
+// even on maps too small to hold them all
+func around(gameMap hlt.GameMap, location hlt.Location, reach int) []hlt.Location {
+	locations := make([]hlt.Location, 0, 2*reach*(reach+1)+1)
+	for dy := max(-reach, -(gameMap.Height-1)/2); dy <= min(reach, gameMap.Height/2); dy++ {
+		for dx := max(abs(dy)-reach, -(gameMap.Width-1)/2); dx <= min(reach-abs(dy), gameMap.Width/2); dx++ {
+			locations = append(locations, hlt.NewLocation(wrap(location.X+dx, gameMap.Width), wrap(location.Y+dy, gameMap.Height)))
+		}
+	}
+	return locations
+}
+
+// siteError is how far owner holding a site with strength is from the cell
+// seen there, a change of owner counting for more than any strength
+func siteError(owner int, strength int, cell *Cell) int {
+	e := abs(strength - cell.Strength)
+	if owner != cell.Owner {
+		e += maxStrength + 1
+	}
+	return e
+}
+
+/*
+███████ ███████  █████  ██████   ██████ ██   ██
+██      ██      ██   ██ ██   ██ ██      ██   ██
+███████ █████   ███████ ██████  ██      ███████
+     ██ ██      ██   ██ ██   ██ ██      ██   ██
+███████ ███████ ██   ██ ██   ██  ██████ ██   ██
+*/
+
+// searchDepth is the default Params.SearchDepth, turns a Search looks ahead
+const searchDepth = 3
+
+// searchWidth is the default Params.SearchWidth, states a Search keeps after each turn
+const searchWidth = 6
+
+// searchSize is the default Params.SearchSize, the width and height of the window
+// a Search simulates, wide enough for an enemy two moves away from the searched cell
+const searchSize = 7
+
+// SearchPolicy picks the direction of a single cell while searching
+type SearchPolicy func(cells *Cells, cell *Cell) hlt.Direction
+
+// Search is a beam search over the joint moves of every cell in a window,
+// simulated several turns ahead with Cells.Simulate. Each turn the owner tries
+// a few joint moves and keeps the states that score best against the worst of
+// the opponents' replies, which shows traps that only spring on a later turn.
+type Search struct {
+	Owner  int
+	Depth  int
+	Width  int
+	Params *Params
+	Budget *Budget
+	// Joint moves tried each turn by the owner and by opponents
+	Policies         []SearchPolicy
+	OpponentPolicies []SearchPolicy
+}
+
+// searchState is a position reached by a Search and the first move that led to it
+type searchState struct {
+	Cells     *Cells
+	Direction hlt.Direction
+	Score     float64
+}
+
+// NewSearch is a constructor
+func NewSearch(owner int, params *Params, budget *Budget) *Search {
+	params = params.orDefault()
+	return &Search{
+		Owner:            owner,
+		Depth:            params.SearchDepth,
+		Width:            params.SearchWidth,
+		Params:           params,
+		Budget:           budget,
+		Policies:         []SearchPolicy{SearchHold, SearchGreedy},
+		OpponentPolicies: []SearchPolicy{SearchHold, SearchGreedy, SearchAdvance},
+	}
+}
+
+// BestMove searches cells for the best direction to move the owner's cell at
+// location, returning the best found so far once the budget expires
+func (s *Search) BestMove(cells *Cells, location hlt.Location) hlt.Direction {
+	beam := make([]searchState, 0, len(hlt.Directions))
+	for _, direction := range hlt.Directions {
+		if !cells.InBounds(cells.GetLocation(location, direction)) {
+			continue
+		}
+		if s.Budget.Expired() {
+			break
+		}
+		moves := s.JointMoves(cells, s.Owner, SearchGreedy)
+		for i := range moves {
+			if moves[i].Location == location {
+				moves[i].Direction = direction
+			}
+		}
+		next, score := s.Reply(cells, cells, moves)
+		beam = append(beam, searchState{Cells: next, Direction: direction, Score: score})
+	}
+	if len(beam) == 0 {
+		return hlt.STILL
+	}
+	beam = s.prune(beam)
+	for turn := 1; turn < s.Depth; turn++ {
+		next := make([]searchState, 0, len(beam)*len(s.Policies))
+		for _, state := range beam {
+			for _, policy := range s.Policies {
+				if s.Budget.Expired() {
+					return beam[0].Direction
+				}
+				moves := s.JointMoves(state.Cells, s.Owner, policy)
+				reached, score := s.Reply(cells, state.Cells, moves)
+				next = append(next, searchState{Cells: reached, Direction: state.Direction, Score: score})
+			}
+		}
+		beam = s.prune(next)
+	}
+	return beam[0].Direction
+}
+
+// prune sorts states best first and keeps Width of them. Ties keep their order,
+// so STILL is preferred over moves that change nothing.
+func (s *Search) prune(states []searchState) []searchState {
+	sort.SliceStable(states, func(i, j int) bool {
+		return states[i].Score > states[j].Score
+	})
+	if len(states) > s.Width {
+		states = states[:s.Width]
+	}
+	return states
+}
+
+// Reply simulates the owner's moves against each of the opponents' joint
+// replies, returning the one leaving the owner with the lowest Score
+func (s *Search) Reply(root *Cells, cells *Cells, moves hlt.MoveSet) (*Cells, float64) {
+	var worst *Cells
+	worstScore := 0.0
+	for _, policy := range s.OpponentPolicies {
+		replies := hlt.MoveSet{}
+		for owner := range cells.ByOwner {
+			if owner != unowned && owner != s.Owner {
+				replies = append(replies, s.JointMoves(cells, owner, policy)...)
+			}
+		}
+		next := cells.Simulate(append(replies, moves...))
+		if score := s.Score(root, next); worst == nil || score < worstScore {
+			worst = next
+			worstScore = score
+		}
+		if len(replies) == 0 {
+			// no opponents, every policy gives the same result
+			break
+		}
+	}
+	return worst, worstScore
+}
+
+// Score is the owner's OwnerScore delta since root, less the deltas of its opponents
+func (s *Search) Score(root *Cells, cells *Cells) float64 {
+	score := 0.0
+	for owner := range root.ByOwner {
+		if owner == unowned {
+			continue
+		}
+		delta := NewDeltaScore(ScoreOf(root, owner), ScoreOf(cells, owner)).SingleScore(s.Params)
+		if owner == s.Owner {
+			score += delta
+		} else {
+			score -= delta
+		}
+	}
+	return score
+}
+
+// ScoreOf is the OwnerScore of owner in cells, zero when owner has no cells there
+func ScoreOf(cells *Cells, owner int) OwnerScore {
+	if ownedCells, ok := cells.ByOwner[owner]; ok {
+		return NewOwnerScore(ownedCells)
+	}
+	return OwnerScore{}
+}
+
+// JointMoves is a move picked by policy for every cell owner has in cells
+func (s *Search) JointMoves(cells *Cells, owner int, policy SearchPolicy) hlt.MoveSet {
+	moves := hlt.MoveSet{}
+	if ownedCells, ok := cells.ByOwner[owner]; ok {
+		for _, cell := range ownedCells.OwnedCells() {
+			moves = append(moves, hlt.Move{Location: cell.Location, Direction: policy(cells, cell)})
+		}
+	}
+	return moves
+}
+
+// SearchHold keeps every cell STILL
+func SearchHold(cells *Cells, cell *Cell) hlt.Direction {
+	return hlt.STILL
+}
+
+// SearchGreedy takes the best neighbor the cell can beat, enemies before neutrals
+// and neutrals by production per strength
+func SearchGreedy(cells *Cells, cell *Cell) hlt.Direction {
+	targetDirection := hlt.STILL
+	targetValue := 0.0
+	for _, direction := range hlt.CARDINALS {
+		neighbor := cells.GetCell(cell.Location, direction)
+		if neighbor == nil || neighbor.Owner == cell.Owner || neighbor.Strength >= cell.Strength {
+			continue
+		}
+		value := float64(neighbor.Production) / float64(max(1, neighbor.Strength))
+		if neighbor.Owner != unowned {
+			value = float64(maxStrength + neighbor.Strength)
+		}
+		if value > targetValue {
+			targetDirection = direction
+			targetValue = value
+		}
+	}
+	return targetDirection
+}
+
+// SearchAdvance steps the cell towards the nearest cell of another player
+func SearchAdvance(cells *Cells, cell *Cell) hlt.Direction {
+	var target *Cell
+	targetDistance := maxCost
+	cells.ForEach(func(other *Cell) {
+		if other.Owner != unowned && other.Owner != cell.Owner {
+			if distance := cells.GameMap.GetDistance(cell.Location, other.Location); distance < targetDistance {
+				target = other
+				targetDistance = distance
+			}
+		}
+	})
+	if target == nil || targetDistance <= 1 {
+		return hlt.STILL
+	}
+	for _, direction := range hlt.CARDINALS {
+		neighbor := cells.GetCell(cell.Location, direction)
+		if neighbor != nil && cells.GameMap.GetDistance(neighbor.Location, target.Location) < targetDistance {
+			return direction
+		}
+	}
+	return hlt.STILL
+}
+
+/*
+ ██████  ██████  ███    ███ ██████   █████  ████████
+██      ██    ██ ████  ████ ██   ██ ██   ██    ██
+██      ██    ██ ██ ████ ██ ██████  ███████    ██
+██      ██    ██ ██  ██  ██ ██   ██ ██   ██    ██
+ ██████  ██████  ██      ██ ██████  ██   ██    ██
+*/
+
+// combatReach is how far around a cell MoveStrategyCombat looks, far enough for
+// an enemy to step next to where the cell moves
+const combatReach = 3
+
+// Battle resolves the fighting of one turn of moves by the rules of the Halite
+// environment and returns the strength each owner loses, unowned being neutral
+// strength destroyed. Cells given no move stay STILL and produce, pieces merge
+// up to maxStrength, then every piece deals its strength to each enemy piece on
+// its own and the adjacent sites and shares damage with a neutral it moves onto.
+// Neutrals never damage their neighbors. Moves leaving the Cells are dropped.
+func (c *Cells) Battle(moves map[int]hlt.MoveSet) map[int]int {
+	pieces, injuries := c.fight(moves)
+	losses := make(map[int]int)
+	for location, owners := range injuries {
+		for owner, damage := range owners {
+			strength := pieces[location][owner]
+			if owner == unowned {
+				strength = c.Get(location.X, location.Y).Strength
+			}
+			losses[owner] += min(strength, damage)
+		}
+	}
+	return losses
+}
+
+// Resolve is the Cells one turn of moves later by the same rules as Battle.
+// Unlike Simulate it damages the neutrals captured and fights pieces that stay
+// STILL. Sites near the edge miss the pieces of cells outside the Cells.
+func (c *Cells) Resolve(moves map[int]hlt.MoveSet) *Cells {
+	pieces, injuries := c.fight(moves)
+	next := c.Clone()
+	for i := range next.Contents {
+		cell := &next.Contents[i]
+		if cell.Owner != unowned {
+			cell.Owner, cell.Strength = unowned, 0
+		} else {
+			cell.Strength = max(0, cell.Strength-injuries[cell.Location][unowned])
+		}
+		// pieces taking damage as big as their strength are destroyed, 0 against 0 too
+		for owner, strength := range pieces[cell.Location] {
+			if damage, ok := injuries[cell.Location][owner]; !ok || damage < strength {
+				cell.Owner, cell.Strength = owner, strength-damage
+			}
+		}
+	}
+	for _, ownedCells := range next.ByOwner {
+		ownedCells.Reset()
+	}
+	for i := range next.Contents {
+		cell := &next.Contents[i]
+		if _, ok := next.ByOwner[cell.Owner]; !ok {
+			next.ByOwner[cell.Owner] = NewOwnedCells()
+		}
+		next.ByOwner[cell.Owner].Add(cell)
+	}
+	return next
+}
+
+// fight gathers the pieces of every owner after moves and the damage each takes
+func (c *Cells) fight(moves map[int]hlt.MoveSet) (Forces, map[hlt.Location]map[int]int) {
+	directions := make([]hlt.Direction, len(c.Contents))
+	for owner, ownerMoves := range moves {
+		for _, move := range ownerMoves {
+			if i := c.Index(move.Location.X, move.Location.Y); i >= 0 && c.Contents[i].Owner == owner {
+				directions[i] = move.Direction
+			}
+		}
+	}
+	pieces := Forces{}
+	for i := range c.Contents {
+		cell := &c.Contents[i]
+		if cell.Owner == unowned {
+			continue
+		}
+		destination := c.GetLocation(cell.Location, directions[i])
+		if directions[i] == hlt.STILL || !c.InBounds(destination) {
+			pieces.Add(cell.Location, cell.Owner, min(maxStrength, cell.Strength+cell.Production))
+			continue
+		}
+		pieces.Add(destination, cell.Owner, cell.Strength)
+		// the site left behind is kept by an empty piece
+		pieces.Add(cell.Location, cell.Owner, 0)
+	}
+	// damage is summed from the strengths before any is dealt
+	injuries := make(map[hlt.Location]map[int]int)
+	injure := func(location hlt.Location, owner int, damage int) {
+		if _, ok := injuries[location]; !ok {
+			injuries[location] = make(map[int]int)
+		}
+		injuries[location][owner] += damage
+	}
+	for location, owners := range pieces {
+		for owner, strength := range owners {
+			for _, direction := range hlt.Directions {
+				target := c.GetLocation(location, direction)
+				for other := range pieces[target] {
+					if other != owner {
+						injure(target, other, strength)
+					}
+				}
+			}
+			if neutral := c.Get(location.X, location.Y); neutral.Owner == unowned && neutral.Strength > 0 {
+				injure(location, owner, neutral.Strength)
+				injure(location, unowned, strength)
+			}
+		}
+	}
+	return pieces, injuries
+}
+
+// CombatScore is the enemy strength destroyed less our own lost in a Battle
+func CombatScore(losses map[int]int, owner int) int {
+	score := 0
+	for other, lost := range losses {
+		if other == owner {
+			score -= lost
+		} else if other != unowned {
+			score += lost
+		}
+	}
+	return score
+}
+
+// MoveStrategyCombat moves cells near an enemy where the Battle in a window
+// around them scores best, on average over the enemy holding or advancing on
+// us. Cells decided earlier this turn keep their moves, the rest
+// of ours stay STILL. Cells away from enemies expand with MoveStrategyV5.
+func (b *Bot) MoveStrategyCombat(cell *Cell) Decision {
+	if !b.nearEnemy(cell, combatReach-1) {
+		return Decision{Move: b.MoveStrategyV5(cell), Strategy: "v5"}
+	}
+	size := 2*combatReach + 1
+	window := NewCells(cell.X-combatReach, cell.Y-combatReach, size, size, b.Cells.GameMap)
+	own := hlt.MoveSet{}
+	for _, decision := range b.Decisions {
+		if window.InBounds(decision.Move.Location) {
+			own = append(own, decision.Move)
+		}
+	}
+	// what each enemy may do: hold everything, or advance everything
+	scenarios := []map[int]hlt.MoveSet{{}, {}}
+	for owner, ownedCells := range window.ByOwner {
+		if owner == unowned || owner == b.Owner {
+			continue
+		}
+		for _, other := range ownedCells.OwnedCells() {
+			scenarios[1][owner] = append(scenarios[1][owner], hlt.Move{Location: other.Location, Direction: SearchAdvance(window, other)})
+		}
+	}
+	best, bestScore := hlt.STILL, 0
+	for _, direction := range hlt.Directions {
+		move := hlt.Move{Location: cell.Location, Direction: direction}
+		// scenarios are equally likely, so their sum ranks moves like the average
+		score := 0
+		for _, scenario := range scenarios {
+			scenario[b.Owner] = append(own[:len(own):len(own)], move)
+			score += CombatScore(window.Battle(scenario), b.Owner)
+		}
+		if direction == hlt.STILL || score > bestScore {
+			best, bestScore = direction, score
+		}
+	}
+	return Decision{Move: hlt.Move{Location: cell.Location, Direction: best}, Strategy: "combat"}
+}
+
+// nearEnemy is true when another player holds a cell within distance of cell
+func (b *Bot) nearEnemy(cell *Cell, distance int) bool {
+	for dy := -distance; dy <= distance; dy++ {
+		for dx := abs(dy) - distance; dx <= distance-abs(dy); dx++ {
+			location := b.Cells.GetSafeLocation(cell.X+dx, cell.Y+dy)
+			if other := b.Cells.Get(location.X, location.Y); other != nil && other.Owner != unowned && other.Owner != b.Owner {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Default Params weights of production, strength and territory
+const pMod = 0.6
+const sMod = 0.2
+const tMod = 0.2
+
+type OwnerScore struct {
+	Production int `json:"production"`
+	Strength   int `json:"strength"`
+	Territory  int `json:"territory"`
+}
+
+// SingleScore weighs the scaled production, strength and territory by params
+func (os OwnerScore) SingleScore(params *Params) float64 {
+	p := params.orDefault()
+	return p.ProductionWeight*(float64(os.Production)/p.ProductionScale) +
+		p.StrengthWeight*(float64(os.Strength)/p.StrengthScale) +
+		p.TerritoryWeight*(float64(os.Territory)/p.TerritoryScale)
+}
+
+func NewOwnerScore(ownedCells *OwnedCells) OwnerScore {
+	return OwnerScore{
+		Production: ownedCells.TotalProduction,
+		Strength:   ownedCells.TotalStrength,
+		Territory:  ownedCells.TotalTerritory,
+	}
+}
+
+func NewDeltaScore(prev OwnerScore, next OwnerScore) OwnerScore {
+	return OwnerScore{
+		Production: next.Production - prev.Production,
+		Strength:   next.Strength - prev.Strength,
+		Territory:  next.Territory - prev.Territory,
+	}
+}
+
+// OwnedCells collection of Cells belonging to the same owner with some stats
+type OwnedCells struct {
+	TotalProduction int
+	TotalStrength   int
+	TotalTerritory  int
+	_cells          []*Cell
+	_borderCells    []*Cell
+	_bodyCells      []*Cell
+	_totalX         int
+	_totalY         int
+	_calcDone       bool
+}
+
+// NewOwnedCells is a constructor
+func NewOwnedCells() *OwnedCells {
+	return &OwnedCells{
+		_cells:       make([]*Cell, 0, 1),
+		_borderCells: make([]*Cell, 0, 1),
+		_bodyCells:   make([]*Cell, 0, 1),
+		_calcDone:    false,
+	}
+}
+
+// Add a cell to the owned cells
+func (o *OwnedCells) Add(cell *Cell) {
+	o.TotalProduction += cell.Production
+	o.TotalStrength += cell.Strength
+	o.TotalTerritory++
+	if len(o._cells) > 0 {
+		// offsets from the first cell, the shortest way around the map
+		first := o._cells[0]
+		o._totalX += shortestOffset(cell.X-first.X, cell.Cells.GameMap.Width)
+		o._totalY += shortestOffset(cell.Y-first.Y, cell.Cells.GameMap.Height)
+	}
+	o._cells = append(o._cells, cell)
+	o._calcDone = false
+}
+
+// Reset removes all owned cells and resets stats
+func (o *OwnedCells) Reset() {
+	o._cells = make([]*Cell, 0, len(o._cells))
+	o.TotalProduction = 0
+	o.TotalStrength = 0
+	o.TotalTerritory = 0
+	o._borderCells = make([]*Cell, 0, len(o._borderCells))
+	o._bodyCells = make([]*Cell, 0, len(o._bodyCells))
+	o._totalX = 0
+	o._totalY = 0
+	o._calcDone = false
+}
+
+// CenterOfMass is the centroid for the owned shape, wrapping around the map.
+// Shapes spanning more than half the map are measured from their first cell.
+func (o *OwnedCells) CenterOfMass() hlt.Location {
+	if len(o._cells) == 0 {
+		return hlt.NewLocation(0, 0)
+	}
+	first := o._cells[0]
+	gameMap := first.Cells.GameMap
+	return hlt.NewLocation(
+		wrap(first.X+o._totalX/len(o._cells), gameMap.Width),
+		wrap(first.Y+o._totalY/len(o._cells), gameMap.Height),
+	)
+}
+
+// shortestOffset is offset wrapped into the range [-size/2, size/2)
+func shortestOffset(offset int, size int) int {
+	return wrap(offset+size/2, size) - size/2
+}
+
+// OwnedCells is the list of all owned cells
+func (o *OwnedCells) OwnedCells() []*Cell {
+	return o._cells
+}
+
+// BorderCells is the list of only border owned cells
+func (o *OwnedCells) BorderCells() []*Cell {
+	if !o._calcDone {
+		o.Calc()
+	}
+	return o._borderCells
+}
+
+// BodyCells is the list of only body owned cells
+func (o *OwnedCells) BodyCells() []*Cell {
+	if !o._calcDone {
+		o.Calc()
+	}
+	return o._bodyCells
+}
+
+// Calc splits the cells into Border and Body lists. Expensive.
+func (o *OwnedCells) Calc() {
+	for _, cell := range o._cells {
+		if cell.Border() {
+			o._borderCells = append(o._borderCells, cell)
+		} else {
+			o._bodyCells = append(o._bodyCells, cell)
+		}
+	}
+	o._calcDone = true
+}
+
+/*
+ ██████ ███████ ██      ██      ███████
+██      ██      ██      ██      ██
+██      █████   ██      ██      ███████
+██      ██      ██      ██           ██
+ ██████ ███████ ███████ ███████ ███████
+*/
+
+// Cells represents a subview of the gameMap. Simulated forward with
+// a set of moves, or updated from turn to turn by a bot.
+type Cells struct {
+	// Contents holds the window row by row, use Index to find a map coordinate
+	Contents []Cell
+	Height   int
+	Width    int
+	X        int
+	Y        int
+	// Original map dimensions
+	GameMap hlt.GameMap
+	// _sourceHeight int
+	// _sourceWidth  int
+	// Ownership changes between turns
+	ByOwner map[int]*OwnedCells
+	// Production stats
+	AvgProduction int
+	MaxProduction int
+	MinProduction int
+}
+
+// NewCells is a constructor
+func NewCells(x int, y int, width int, height int, gameMap hlt.GameMap) *Cells {
+	x = wrap(x, gameMap.Width)
+	y = wrap(y, gameMap.Height)
+	width = min(width, gameMap.Width)
+	height = min(height, gameMap.Height)
+	cells := &Cells{
+		Height:  height,
+		Width:   width,
+		X:       x,
+		Y:       y,
+		GameMap: gameMap,
+		// _sourceHeight: gameMap.Height,
+		// _sourceWidth:  gameMap.Width,
+		ByOwner:       make(map[int]*OwnedCells),
+		AvgProduction: 0,
+		MaxProduction: 0,
+		MinProduction: 255,
+	}
+	cells.Contents = make([]Cell, width*height)
+	sum := 0
+	for i := range cells.Contents {
+		xf, yf := cells.coordinates(i)
+		site := gameMap.Contents[yf][xf]
+		cells.Contents[i] = *NewCell(cells, site, xf, yf)
+		cell := &cells.Contents[i]
+		// Add to Owner's OwnedCells
+		if _, ok := cells.ByOwner[site.Owner]; !ok {
+			cells.ByOwner[site.Owner] = NewOwnedCells()
+		}
+		cells.ByOwner[site.Owner].Add(cell)
+		// update Max
+		if site.Production > cells.MaxProduction {
+			cells.MaxProduction = site.Production
+		}
+		// update Min
+		if site.Production < cells.MinProduction {
+			cells.MinProduction = site.Production
+		}
+		// update Sum for Avg
+		sum += site.Production
+	}
+	cells.AvgProduction = sum / (width * height)
+	return cells
+}
+
+// wrap returns value modulo size in the range [0, size)
+func wrap(value int, size int) int {
+	return ((value % size) + size) % size
+}
+
+// Index returns the position of map coordinate x, y in Contents, or -1 when it
+// falls outside the Cells. Coordinates wrap around the map.
+func (c *Cells) Index(x int, y int) int {
+	dx := wrap(x-c.X, c.GameMap.Width)
+	dy := wrap(y-c.Y, c.GameMap.Height)
+	if dx >= c.Width || dy >= c.Height {
+		return -1
+	}
+	return dy*c.Width + dx
+}
+
+// coordinates is the inverse of Index, the map coordinate of a position in Contents
+func (c *Cells) coordinates(index int) (int, int) {
+	return (c.X + index%c.Width) % c.GameMap.Width, (c.Y + index/c.Width) % c.GameMap.Height
+}
+
+// NewCellsFromReplay is a constructor for Cells covering the whole map on the given
+// turn of a recorded game
+func NewCellsFromReplay(r *replay.Replay, turn int) (*Cells, error) {
+	gameMap, err := r.GameMap(turn)
+	if err != nil {
+		return nil, err
+	}
+	return NewCells(0, 0, gameMap.Width, gameMap.Height, gameMap), nil
+}
+
+// Clone produces a copy of the Cells containing new copies of all contained cells
+func (c *Cells) Clone() *Cells {
+	clone := &Cells{
+		Height:        c.Height,
+		Width:         c.Width,
+		X:             c.X,
+		Y:             c.Y,
+		GameMap:       c.GameMap,
+		ByOwner:       make(map[int]*OwnedCells),
+		AvgProduction: c.AvgProduction,
+		MaxProduction: c.MaxProduction,
+		MinProduction: c.MinProduction,
+	}
+	clone.Contents = make([]Cell, len(c.Contents))
+	for i := range c.Contents {
+		clone.Contents[i] = *c.Contents[i].Clone(clone)
+		cell := &clone.Contents[i]
+		// Add to Owner's OwnedCells
+		if _, ok := clone.ByOwner[cell.Owner]; !ok {
+			clone.ByOwner[cell.Owner] = NewOwnedCells()
+		}
+		clone.ByOwner[cell.Owner].Add(cell)
+	}
+	return clone
+}
+
+// Update Cells with Site data from provided GameMap
+func (c *Cells) Update(gameMap hlt.GameMap) {
+	// keep the latest sites, windows made with NewCells read from it
+	c.GameMap = gameMap
+	for _, ownedCells := range c.ByOwner {
+		ownedCells.Reset()
+	}
+	// Cells my not be the full size of gameMap, only iterate Cells contents
+	for i := range c.Contents {
+		cell := &c.Contents[i]
+		site := gameMap.Contents[cell.Y][cell.X]
+		cell.Update(site)
+		// Add to Owner's OwnedCells
+		if _, ok := c.ByOwner[site.Owner]; !ok {
+			c.ByOwner[site.Owner] = NewOwnedCells()
+		}
+		c.ByOwner[site.Owner].Add(cell)
+	}
+}
+
+// Forces is the strength each owner brings to a location in one turn
+type Forces map[hlt.Location]map[int]int
+
+// Add merges strength into the owner's force at location. Like the environment
+// merging pieces, the force never goes over maxStrength.
+func (f Forces) Add(location hlt.Location, owner int, strength int) {
+	if _, ok := f[location]; !ok {
+		f[location] = make(map[int]int)
+	}
+	f[location][owner] = min(maxStrength, f[location][owner]+strength)
+}
+
+// Simulate applies moves in the same way halite.io would... I think.
+func (c *Cells) Simulate(moves hlt.MoveSet) *Cells {
+	clone := c.Clone()
+	// cells moving this round, by Index, every other piece produces before combat
+	moving := make([]bool, len(clone.Contents))
+	for _, move := range moves {
+		if move.Direction != hlt.STILL {
+			moving[clone.Index(move.Location.X, move.Location.Y)] = true
+		}
+	}
+	for i := range clone.Contents {
+		if cell := &clone.Contents[i]; !moving[i] && cell.Owner != unowned {
+			cell.Strength = min(maxStrength, cell.Strength+cell.Production)
+		}
+	}
+	// forces which have moved or been recruited by moving forces,
+	// and will attack destination + Cardinal opposing forces
+	activeForces := Forces{}
+	// forces moving into new cells
+	for _, move := range moves {
+		if move.Direction != hlt.STILL {
+			fromCell := clone.Get(move.Location.X, move.Location.Y)
+			toCell := clone.GetCell(move.Location, move.Direction)
+			// combine strength from one owner coming from multiple cells to a max of 255
+			activeForces.Add(toCell.Location, fromCell.Owner, fromCell.Strength)
+			fromCell.Strength = 0
+		}
+	}
+	// forces which are brought into conflict by orthogonally adjacent active forces
+	passiveForces := Forces{}
+	// forces moving into new cells
+	for _, move := range moves {
+		if move.Direction != hlt.STILL {
+			toCell := clone.GetCell(move.Location, move.Direction)
+			for _, direction := range hlt.Directions {
+				if clone.InBounds(clone.GetLocation(toCell.Location, direction)) {
+					neighborCell := clone.GetCell(toCell.Location, direction)
+					if _, ok := activeForces[neighborCell.Location][neighborCell.Owner]; ok {
+						activeForces.Add(neighborCell.Location, neighborCell.Owner, neighborCell.Strength)
+						neighborCell.Strength = 0
+					} else {
+						// combine strength from one owner coming from multiple cells to a max of 255
+						passiveForces.Add(neighborCell.Location, neighborCell.Owner, neighborCell.Strength)
+						neighborCell.Strength = 0
+					}
+				}
+			}
+		}
+	}
+	// generate effects caused by all active and passive forces
+	effect := make(map[hlt.Location]map[int]int)
+	// combine passive and active forces
+	for location, activeCellForces := range activeForces {
+		cell := clone.Get(location.X, location.Y)
+		if _, ok := effect[location]; !ok {
+			effect[location] = make(map[int]int)
+		}
+		for owner, activeCellForce := range activeCellForces {
+			for _, direction := range hlt.Directions {
+				if clone.InBounds(clone.GetLocation(cell.Location, direction)) {
+					otherCell := clone.GetCell(cell.Location, direction)
+					for otherOwner := range activeForces[otherCell.Location] {
+						if otherOwner != owner {
+							if _, ok := effect[otherCell.Location]; !ok {
+								effect[otherCell.Location] = make(map[int]int)
+							}
+							effect[otherCell.Location][otherOwner] += activeCellForce
+							// Cell was in conflict, it is possible there will not be an owner here following combat
+							otherCell.Owner = unowned
+						}
+					}
+					for otherOwner, otherPassiveCellForce := range passiveForces[otherCell.Location] {
+						if otherOwner != owner {
+							if _, ok := effect[otherCell.Location]; !ok {
+								effect[otherCell.Location] = make(map[int]int)
+							}
+							effect[otherCell.Location][otherOwner] += activeCellForce
+							if otherOwner != unowned {
+								effect[cell.Location][owner] += otherPassiveCellForce
+							}
+							// Cell was in conflict, it is possible there will not be an owner here following combat
+							otherCell.Owner = unowned
+						}
+					}
+				}
+			}
+		}
+	}
+	// apply effects
+	for location, cellEffects := range effect {
+		for owner, effect := range cellEffects {
+			if force, ok := activeForces[location][owner]; ok {
+				activeForces[location][owner] = max(0, force-effect)
+			}
+			if force, ok := passiveForces[location][owner]; ok {
+				passiveForces[location][owner] = max(0, force-effect)
+			}
+		}
+	}
+	for location, remainingActiveForces := range activeForces {
+		cell := clone.Get(location.X, location.Y)
+		for owner, remainingForce := range remainingActiveForces {
+			if remainingForce > 0 {
+				cell.Owner = owner
+				cell.Strength = remainingForce
+			}
+		}
+	}
+	for location, remainingPassiveForces := range passiveForces {
+		cell := clone.Get(location.X, location.Y)
+		for owner, remainingForce := range remainingPassiveForces {
+			if remainingForce > 0 {
+				cell.Owner = owner
+				cell.Strength = remainingForce
+			}
+		}
+	}
+	// This sucks but we now have to go through and reassign all OwnedCells
+	for _, ownedCells := range clone.ByOwner {
+		ownedCells.Reset()
+	}
+	for i := range clone.Contents {
+		cell := &clone.Contents[i]
+		// Add to Owner's OwnedCells
+		if _, ok := clone.ByOwner[cell.Owner]; !ok {
+			clone.ByOwner[cell.Owner] = NewOwnedCells()
+		}
+		clone.ByOwner[cell.Owner].Add(cell)
+	}
+	return clone
+}
+
+// InBounds allows the user to check if a location is within the Cells bounds
+func (c *Cells) InBounds(location hlt.Location) bool {
+	return c.Index(location.X, location.Y) >= 0
+}
+
+// GetLocation returns a Location for the requested Location
+func (c *Cells) GetLocation(location hlt.Location, direction hlt.Direction) hlt.Location {
+	return c.GameMap.GetLocation(location, direction)
+}
+
+// GetCell returns a Cell for the given Locations
+func (c *Cells) GetCell(location hlt.Location, direction hlt.Direction) *Cell {
+	loc := c.GetLocation(location, direction)
+	return c.Get(loc.X, loc.Y)
+}
+
+// GetCells returns all the cells that pass the provided test
+func (c *Cells) GetCells(cellTest CellTest) []*Cell {
+	results := make([]*Cell, 0, 1)
+	for i := range c.Contents {
+		if cell := &c.Contents[i]; cellTest(cell) {
+			results = append(results, cell)
+		}
+	}
+	return results
+}
+
+// ForEach performs some function for each cell in Cells
+func (c *Cells) ForEach(fn func(cell *Cell)) {
+	for i := range c.Contents {
+		fn(&c.Contents[i])
+	}
+}
+
+// GetHighestProductionCells returns cells where Production == Cells.MaxProduction
+func (c *Cells) GetHighestProductionCells() []*Cell {
+	return c.GetCells(func(cell *Cell) bool {
+		return cell.Production == c.MaxProduction
+	})
+}
+
+// Get returns the cell for a given x, y coordinate, nil if it is outside the Cells
+func (c *Cells) Get(x int, y int) *Cell {
+	i := c.Index(x, y)
+	if i < 0 {
+		return nil
+	}
+	return &c.Contents[i]
+}
+
+// GetSafeLocation returns the location as one that is InBounds
+func (c *Cells) GetSafeLocation(x, y int) hlt.Location {
+	height := c.GameMap.Height
+	width := c.GameMap.Width
+	return hlt.NewLocation((x+width)%width, (y+height)%height)
+}
+
+// String convert the Cells into a string
+func (c *Cells) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("Cells(x:%d, y:%d, w:%d, h:%d)\n", c.X, c.Y, c.Width, c.Height))
+	for i := range c.Contents {
+		buffer.WriteString(fmt.Sprintf("%v, ", c.Contents[i].String()))
+		if (i+1)%c.Width == 0 {
+			buffer.WriteString("\n")
+		}
+	}
+	return buffer.String()
+}
+
+/*
+ ██████ ███████ ██      ██
+██      ██      ██      ██
+██      █████   ██      ██
+██      ██      ██      ██
+ ██████ ███████ ███████ ███████
+*/
+
+// Cell is a combination of Location and Site
+type Cell struct {
+	Cells      *Cells
+	Location   hlt.Location
+	Y          int
+	X          int
+	Owner      int
+	Strength   int
+	Production int
+	_calcDone  bool
+	_border    bool
+}
+
+// NewCell is a constructor
+func NewCell(cells *Cells, site hlt.Site, x int, y int) *Cell {
+	return &Cell{
+		Cells:      cells,
+		Location:   hlt.NewLocation(x, y),
+		Y:          y,
+		X:          x,
+		Owner:      site.Owner,
+		Strength:   site.Strength,
+		Production: site.Production,
+		_calcDone:  false,
+	}
+}
+
+// Clone produces a copy of the given Cell
+func (c *Cell) Clone(cells *Cells) *Cell {
+	return &Cell{
+		Cells:      cells,
+		Location:   c.Location,
+		Y:          c.Y,
+		X:          c.X,
+		Owner:      c.Owner,
+		Strength:   c.Strength,
+		Production: c.Production,
+		_calcDone:  false,
+	}
+}
+
+// Update to reflect the new Site info
+func (c *Cell) Update(site hlt.Site) {
+	c.Owner = site.Owner
+	c.Production = site.Production
+	c.Strength = site.Strength
+	c._calcDone = false
+}
+
+// Site returns a hlt.Site for the Cell
+func (c *Cell) Site() hlt.Site {
+	return hlt.Site{
+		Owner:      c.Owner,
+		Strength:   c.Strength,
+		Production: c.Production,
+	}
+}
+
+// GetNeighbor returns a list of surrounding cells
+func (c *Cell) GetNeighbor(direction hlt.Direction) *Cell {
+	return c.Cells.GetCell(c.Location, direction)
+}
+
+// Neighbors returns a cell in the given direction from this one
+func (c *Cell) Neighbors() []*Cell {
+	cells := make([]*Cell, 0, 4)
+	for _, direction := range hlt.CARDINALS {
+		cells = append(cells, c.GetNeighbor(direction))
+		// cells[i] = c.GetNeighbor(direction)
+	}
+	return cells
+}
+
+// Border is true if the Cell has at least one neighbor not owned by the Cell's owner
+func (c *Cell) Border() bool {
+	if !c._calcDone {
+		c.Calc()
+	}
+	return c._border
+}
+
+// Heuristic prod / strength or overkill
+func (c *Cell) Heuristic(owner int) float64 {
+	if c.Owner == 0 && c.Strength > 0 {
+		return float64(c.Production) / float64(c.Strength)
+	}
+	return float64(c.TotalDamage(owner))
+}
+
+func (c *Cell) TotalDamage(owner int) int {
+	totalDamage := c.Strength
+	for _, neighbor := range c.Neighbors() {
+		if neighbor.Owner != unowned && neighbor.Owner != owner {
+			totalDamage += neighbor.Strength
+		}
+	}
+	return totalDamage
+}
+
+// Overkill is the enemy strength destroyed minus strength lost by a piece of
+// the given strength moving onto the cell while every other piece holds. The
+// piece damages each enemy on the cell and its neighbors and is damaged by all
+// of them, and by a neutral on the cell.
+func (c *Cell) Overkill(owner int, strength int) int {
+	strengthLost := 0
+	strengthTaken := 0
+	if c.Owner == unowned {
+		strengthLost += c.Strength
+	} else if c.Owner != owner {
+		strengthLost += c.Strength
+		strengthTaken += min(strength, c.Strength)
+	}
+	for _, neighbor := range c.Neighbors() {
+		if neighbor.Owner != owner && neighbor.Owner != unowned {
+			strengthLost += neighbor.Strength
+			strengthTaken += min(strength, neighbor.Strength)
+		}
+	}
+	// Attacker can lose at most their initial strength
+	strengthLost = min(strength, strengthLost)
+	return strengthTaken - strengthLost
+}
+
+// Calc evaluates sites orthogonal to the given cell
+func (c *Cell) Calc() {
+	border := false
+	for _, neighbor := range c.Neighbors() {
+		if neighbor.Owner != c.Owner {
+			border = true
+		}
+	}
+	c._border = border
+}
+
+func (c *Cell) String() string {
+	return fmt.Sprintf("(x:%d, y:%d)[o:%d, p:%d, s:%d]", c.X, c.Y, c.Owner, c.Production, c.Strength)
+}
@@ -0,0 +1,141 @@
+// Command flowdump renders one of a bot's flow fields on every turn of a
+// recorded game. The bot replays the game as -owner and each turn's field is
+// drawn into dir as frame-0000.png, frame-0001.png and so on (or .svg), which
+// ffmpeg -i frame-%04d.png stitches together. With -gif the PNG frames are also
+// collected into animation.gif. The bot's Params flags are taken as well:
+//
+//	go run ./cmd/flowdump -replay game.hlt -owner 2 -flow threat:1 -dir flows -plan-horizon 5
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"halite/bot"
+	"halite/render"
+	"halite/replay"
+)
+
+func main() {
+	replayPath := flag.String("replay", "", "recorded game to render")
+	owner := flag.Int("owner", 1, "player whose flows to render")
+	flow := flag.String("flow", "body", "flow to render: body, prod or threat:<owner>")
+	dir := flag.String("dir", "flows", "directory to write frames into")
+	format := flag.String("format", "png", "format of frames: png or svg")
+	animate := flag.Bool("gif", false, "also collect the png frames into animation.gif")
+	cellSize := flag.Int("cell-size", render.CellSize, "width and height in pixels of a cell")
+	params := bot.DefaultParams()
+	params.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *replayPath == "" {
+		fail(fmt.Errorf("-replay is needed"))
+	}
+	if *format != "png" && *format != "svg" {
+		fail(fmt.Errorf("unknown format %q, have png or svg", *format))
+	}
+	if *animate && *format != "png" {
+		fail(fmt.Errorf("-gif needs png frames"))
+	}
+	if err := params.Validate(); err != nil {
+		fail(err)
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		fail(err)
+	}
+	if err := dump(*replayPath, *owner, *flow, params, *dir, *format, *animate, *cellSize); err != nil {
+		fail(err)
+	}
+}
+
+// dump replays the owner's bot on every turn of the recorded game and writes a
+// frame of the named flow for each
+func dump(replayPath string, owner int, flow string, params *bot.Params, dir string, format string, animate bool, size int) error {
+	r, err := replay.Load(replayPath)
+	if err != nil {
+		return err
+	}
+	player, err := bot.NewBotFromReplay(owner, r, 0)
+	if err != nil {
+		return err
+	}
+	player.Params = params
+	var frames []*image.Paletted
+	for turn := 0; turn < r.NumFrames; turn++ {
+		gameMap, err := r.GameMap(turn)
+		if err != nil {
+			return err
+		}
+		player.Update(gameMap)
+		field, err := player.Flow(flow)
+		if err != nil {
+			return err
+		}
+		frame, err := writeFrame(render.FlowGrid(field, player.Cells, turn), dir, format, size)
+		if err != nil {
+			return err
+		}
+		if animate {
+			frames = append(frames, frame)
+		}
+	}
+	if animate {
+		return writeGIF(frames, filepath.Join(dir, "animation.gif"))
+	}
+	return nil
+}
+
+// writeFrame writes the grid as frame-<turn>.<format>, returning the PNG frame
+// with a GIF palette
+func writeFrame(grid *render.Grid, dir string, format string, size int) (*image.Paletted, error) {
+	f, err := os.Create(filepath.Join(dir, fmt.Sprintf("frame-%04d.%s", grid.Turn, format)))
+	if err != nil {
+		return nil, err
+	}
+	var frame *image.Paletted
+	if format == "svg" {
+		err = grid.WriteSVG(f, size)
+	} else {
+		img := grid.Image(size)
+		frame = image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.Draw(frame, frame.Bounds(), img, image.Point{}, draw.Src)
+		err = png.Encode(f, img)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return frame, f.Close()
+}
+
+// writeGIF animates the frames a tenth of a second apart
+func writeGIF(frames []*image.Paletted, path string) error {
+	if len(frames) == 0 {
+		return nil
+	}
+	delays := make([]int, len(frames))
+	for i := range delays {
+		delays[i] = 10
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(f, &gif.GIF{Image: frames, Delay: delays}); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,121 @@
+// Command tournament plays seeded local matches between bot versions and
+// reports win rates, average final territory and Elo ratings.
+//
+// Each entrant is given as name=bot, where bot is a Go package directory (built
+// once before the tournament), a .py file run with python3, or any other
+// command line speaking the hlt protocol:
+//
+//	go run ./cmd/tournament -games 50 current=. v7=./v7 v5=./v5
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"halite/engine"
+	"halite/tournament"
+)
+
+func main() {
+	games := flag.Int("games", 20, "number of games to play")
+	players := flag.Int("players", 2, "players per game")
+	seed := flag.Int64("seed", 1, "seed of the first game, game i uses seed + i")
+	width := flag.Int("width", 0, "map width, 0 picks a halite.io size per game")
+	height := flag.Int("height", 0, "map height, 0 picks a halite.io size per game")
+	turns := flag.Int("turns", 0, "turn limit, 0 uses the Halite environment's limit")
+	parallel := flag.Int("parallel", 1, "games to run at once")
+	verbose := flag.Bool("v", false, "print the result of every game")
+	flag.Parse()
+
+	specs := flag.Args()
+	if len(specs) == 0 {
+		specs = []string{"current=.", "v7=./v7", "v5=./v5"}
+	}
+	buildDir, err := os.MkdirTemp("", "tournament")
+	if err != nil {
+		fail(err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	entrants := make([]tournament.Entrant, 0, len(specs))
+	names := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		entrant, err := newEntrant(spec, buildDir, names)
+		if err != nil {
+			os.RemoveAll(buildDir)
+			fail(err)
+		}
+		entrants = append(entrants, entrant)
+	}
+
+	standings, played, err := tournament.Run(tournament.Config{
+		Games:    *games,
+		Players:  *players,
+		Seed:     *seed,
+		Width:    *width,
+		Height:   *height,
+		MaxTurns: *turns,
+		Parallel: *parallel,
+	}, entrants)
+	if *verbose {
+		for _, game := range played {
+			// a failed game has no result to report seat by seat
+			if game.Err != nil {
+				fmt.Printf("game %d seed %d: %v\n", game.Index, game.Seed, game.Err)
+				continue
+			}
+			for owner, entrant := range game.Seats {
+				player := game.Result.Players[owner]
+				fmt.Printf("game %d seed %d: %s rank %d territory %d", game.Index, game.Seed, entrants[entrant].Name, player.Rank, player.Territory)
+				if player.Err != nil {
+					fmt.Printf(" (%v)", player.Err)
+				}
+				fmt.Println()
+			}
+		}
+	}
+	if err != nil {
+		os.RemoveAll(buildDir)
+		fail(err)
+	}
+	tournament.WriteTable(os.Stdout, standings)
+}
+
+// newEntrant turns name=bot into an Entrant that runs bot as a subprocess. Names
+// already taken are rejected, a built bot is kept in buildDir under its name.
+func newEntrant(spec string, buildDir string, names map[string]bool) (tournament.Entrant, error) {
+	parts := strings.SplitN(spec, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return tournament.Entrant{}, fmt.Errorf("entrant %q should be name=bot", spec)
+	}
+	name, bot := parts[0], parts[1]
+	if names[name] {
+		return tournament.Entrant{}, fmt.Errorf("entrant name %q is used twice", name)
+	}
+	names[name] = true
+	var command []string
+	if info, err := os.Stat(bot); err == nil && info.IsDir() {
+		binary := filepath.Join(buildDir, name)
+		build := exec.Command("go", "build", "-o", binary, bot)
+		build.Stdout = os.Stderr
+		build.Stderr = os.Stderr
+		if err := build.Run(); err != nil {
+			return tournament.Entrant{}, fmt.Errorf("building %s: %v", bot, err)
+		}
+		command = []string{binary}
+	} else if strings.HasSuffix(bot, ".py") {
+		command = []string{"python3", bot}
+	} else {
+		command = strings.Fields(bot)
+	}
+	return tournament.Entrant{Name: name, NewBot: engine.NewProcessBot(command)}, nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
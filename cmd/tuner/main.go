@@ -0,0 +1,124 @@
+// Command tuner searches the bot's Params with a genetic algorithm in
+// self-play. Each generation of parameter sets plays a local tournament against
+// the starting set. The leaderboard is printed as it goes and the best set is
+// written where the bot's -params flag can load it:
+//
+//	go run ./cmd/tuner -generations 10 -out params.json
+//	./MyBot -params params.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"halite/engine"
+	"halite/tournament"
+	"halite/tuner"
+)
+
+// defaultSpace mirrors bot.DefaultParams, starting every dimension from the
+// value the bot plays with
+var defaultSpace = []tuner.Dimension{
+	{Name: "production_weight", Min: 0, Max: 1, Start: 0.6},
+	{Name: "strength_weight", Min: 0, Max: 1, Start: 0.2},
+	{Name: "territory_weight", Min: 0, Max: 1, Start: 0.2},
+	{Name: "sim_size", Min: 3, Max: 7, Start: 5, Integer: true},
+	{Name: "body_wait", Min: 1, Max: 12, Start: 5, Integer: true},
+	{Name: "plan_horizon", Min: 0, Max: 20, Start: 10, Integer: true},
+	{Name: "wall_ratio", Min: 0, Max: 4, Start: 2},
+}
+
+func main() {
+	generations := flag.Int("generations", 5, "generations to evolve")
+	population := flag.Int("population", 6, "parameter sets per generation")
+	mutation := flag.Float64("mutation", 0.1, "mutation size as a fraction of each dimension's range")
+	games := flag.Int("games", 30, "games played per generation")
+	players := flag.Int("players", 2, "players per game")
+	seed := flag.Int64("seed", 1, "seed of the search and of the first game")
+	width := flag.Int("width", 0, "map width, 0 picks a halite.io size per game")
+	height := flag.Int("height", 0, "map height, 0 picks a halite.io size per game")
+	turns := flag.Int("turns", 0, "turn limit, 0 uses the Halite environment's limit")
+	parallel := flag.Int("parallel", 1, "games to run at once")
+	bot := flag.String("bot", ".", "package directory of the bot to tune")
+	spaceFile := flag.String("space", "", "JSON file of dimensions to search, defaults to the bot's score weights, sim_size, body_wait, plan_horizon and wall_ratio")
+	out := flag.String("out", "params.json", "file the best parameters are written to")
+	top := flag.Int("top", 10, "leaderboard entries to print")
+	flag.Parse()
+
+	space := defaultSpace
+	if *spaceFile != "" {
+		data, err := os.ReadFile(*spaceFile)
+		if err != nil {
+			fail(err)
+		}
+		if err := json.Unmarshal(data, &space); err != nil {
+			fail(fmt.Errorf("%s: %v", *spaceFile, err))
+		}
+	}
+	workDir, err := os.MkdirTemp("", "tuner")
+	if err != nil {
+		fail(err)
+	}
+	defer os.RemoveAll(workDir)
+	binary := filepath.Join(workDir, "bot")
+	build := exec.Command("go", "build", "-o", binary, *bot)
+	build.Stdout = os.Stderr
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		os.RemoveAll(workDir)
+		fail(fmt.Errorf("building %s: %v", *bot, err))
+	}
+
+	newBot := func(name string, params tuner.Params) (engine.NewBot, error) {
+		path := filepath.Join(workDir, name+".json")
+		if err := params.Save(path); err != nil {
+			return nil, err
+		}
+		return engine.NewProcessBot([]string{binary, "-params", path}), nil
+	}
+	config := tuner.Config{
+		Generations: *generations,
+		Population:  *population,
+		Mutation:    *mutation,
+		Tournament: tournament.Config{
+			Games:    *games,
+			Players:  *players,
+			Seed:     *seed,
+			Width:    *width,
+			Height:   *height,
+			MaxTurns: *turns,
+			Parallel: *parallel,
+		},
+	}
+	board, err := tuner.Run(config, space, newBot, func(generation int, board tuner.Leaderboard) {
+		fmt.Printf("generation %d\n", generation)
+		tuner.WriteTable(os.Stdout, board, space, *top)
+		fmt.Println()
+	})
+	if err != nil {
+		os.RemoveAll(workDir)
+		fail(err)
+	}
+	best, ok := board.Best()
+	if !ok {
+		return
+	}
+	if best.Advantage <= 0 {
+		fmt.Println("no candidate beat the starting parameters, writing them instead")
+		best.Params = tuner.Start(space)
+	}
+	if err := best.Params.Save(*out); err != nil {
+		os.RemoveAll(workDir)
+		fail(err)
+	}
+	fmt.Printf("best parameters written to %s\n", *out)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
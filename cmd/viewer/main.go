@@ -0,0 +1,60 @@
+// Command viewer steps through a recorded game in the terminal as one of its
+// players, replaying the bot with the given strategies and Params on every
+// frame. Enter or n moves on a turn, ? lists the other commands:
+//
+//	go run ./cmd/viewer -replay game.hlt -owner 2 -plan-horizon 5
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"halite/bot"
+	"halite/replay"
+	"halite/viewer"
+)
+
+func main() {
+	replayPath := flag.String("replay", "", "recorded game to step through")
+	owner := flag.Int("owner", 1, "player to replay the bot as")
+	border := flag.String("border", bot.DefaultBorderStrategy, fmt.Sprintf("border cell strategy, one of %v", bot.StrategyNames()))
+	body := flag.String("body", bot.DefaultBodyStrategy, fmt.Sprintf("body cell strategy, one of %v", bot.StrategyNames()))
+	params := bot.DefaultParams()
+	params.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *replayPath == "" {
+		fail(fmt.Errorf("-replay is needed"))
+	}
+	if err := params.Validate(); err != nil {
+		fail(err)
+	}
+	r, err := replay.Load(*replayPath)
+	if err != nil {
+		fail(err)
+	}
+	v, err := viewer.NewViewer(r, *owner)
+	if err != nil {
+		fail(err)
+	}
+	v.Bot.Params = params
+	if err := v.Bot.UseStrategies(*border, *body); err != nil {
+		fail(err)
+	}
+	if err := v.Seek(0); err != nil {
+		fail(err)
+	}
+	// color only when writing to a terminal
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		v.Color = true
+	}
+	if err := v.Run(os.Stdin, os.Stdout); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,255 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"halite/hlt"
+)
+
+// Bot is anything that can be shown each frame and answer with moves, the
+// same shape as bot.Bot.
+type Bot interface {
+	Update(gameMap hlt.GameMap)
+	Moves() hlt.MoveSet
+}
+
+// NewBot constructs a Bot playing as owner from the initial map, like the
+// init phase of the Halite environment.
+type NewBot func(owner int, gameMap hlt.GameMap) Bot
+
+// Config describes the match to run. Map, if set, is played as given and must
+// already contain one or more sites for each player tag 1..len(bots); otherwise
+// a Width x Height map is generated from Seed.
+type Config struct {
+	Width    int
+	Height   int
+	Seed     int64
+	MaxTurns int
+	Map      *hlt.GameMap
+}
+
+// PlayerResult is the final standing of one player
+type PlayerResult struct {
+	Owner      int
+	Rank       int
+	Territory  int
+	Production int
+	Strength   int
+	// LastTurnAlive is the last turn the player held territory
+	LastTurnAlive int
+	// Err is set if the bot panicked, which eliminates it
+	Err error
+}
+
+// Result of a finished game, Players is indexed by owner - 1
+type Result struct {
+	Turns   int
+	Players []PlayerResult
+}
+
+// Game is a match in progress between in-process bots.
+type Game struct {
+	Map      hlt.GameMap
+	Turn     int
+	MaxTurns int
+	// Observers are called after every turn with the map the moves were made
+	// on, the moves of each player and the resulting map
+	Observers []func(turn int, gameMap hlt.GameMap, moves map[int]hlt.MoveSet, next hlt.GameMap)
+	bots      []Bot
+	results   []PlayerResult
+}
+
+// NewGame is a constructor. Each bot is constructed with its player tag (its
+// index + 1) and a copy of the initial map.
+func NewGame(config Config, bots ...NewBot) (*Game, error) {
+	if len(bots) == 0 {
+		return nil, errors.New("engine: a game needs at least one bot")
+	}
+	var gameMap hlt.GameMap
+	if config.Map != nil {
+		gameMap = config.Map.Clone()
+		territories := Territories(gameMap)
+		for owner := 1; owner <= len(bots); owner++ {
+			if territories[owner] == 0 {
+				return nil, fmt.Errorf("engine: map has no starting site for player %d", owner)
+			}
+		}
+	} else {
+		if config.Width <= 0 || config.Height <= 0 {
+			return nil, fmt.Errorf("engine: bad map size %dx%d", config.Width, config.Height)
+		}
+		gameMap = GenerateMap(config.Width, config.Height, len(bots), config.Seed)
+	}
+	maxTurns := config.MaxTurns
+	if maxTurns <= 0 {
+		maxTurns = DefaultMaxTurns(gameMap)
+	}
+	game := &Game{
+		Map:      gameMap,
+		Turn:     0,
+		MaxTurns: maxTurns,
+		bots:     make([]Bot, len(bots)),
+		results:  make([]PlayerResult, len(bots)),
+	}
+	for i, newBot := range bots {
+		owner := i + 1
+		game.results[i].Owner = owner
+		game.bots[i] = game.construct(owner, newBot)
+	}
+	return game, nil
+}
+
+// DefaultMaxTurns is the turn limit used by the Halite environment
+func DefaultMaxTurns(gameMap hlt.GameMap) int {
+	return int(10 * math.Sqrt(float64(gameMap.Width*gameMap.Height)))
+}
+
+func (g *Game) construct(owner int, newBot NewBot) (bot Bot) {
+	defer func() {
+		if r := recover(); r != nil {
+			g.eliminate(owner, fmt.Errorf("engine: player %d panicked during init: %v", owner, r))
+			bot = nil
+		}
+	}()
+	return newBot(owner, g.Map.Clone())
+}
+
+// moves asks a single bot for its moves, eliminating it if it panics
+func (g *Game) moves(owner int) (moves hlt.MoveSet) {
+	defer func() {
+		if r := recover(); r != nil {
+			g.eliminate(owner, fmt.Errorf("engine: player %d panicked on turn %d: %v", owner, g.Turn, r))
+			moves = nil
+		}
+	}()
+	bot := g.bots[owner-1]
+	bot.Update(g.Map.Clone())
+	return bot.Moves()
+}
+
+// eliminate removes a misbehaving bot, its sites become unowned
+func (g *Game) eliminate(owner int, err error) {
+	closeBot(g.bots[owner-1])
+	g.bots[owner-1] = nil
+	g.results[owner-1].Err = err
+	for y := range g.Map.Contents {
+		for x := range g.Map.Contents[y] {
+			if g.Map.Contents[y][x].Owner == owner {
+				g.Map.Contents[y][x].Owner = 0
+			}
+		}
+	}
+}
+
+// Alive returns the tags of players still holding territory
+func (g *Game) Alive() []int {
+	territories := Territories(g.Map)
+	alive := make([]int, 0, len(g.bots))
+	for i, bot := range g.bots {
+		if bot != nil && territories[i+1] > 0 {
+			alive = append(alive, i+1)
+		}
+	}
+	return alive
+}
+
+// Done is true once the turn limit is reached or at most one player remains
+// (a single player game runs until the turn limit or its own elimination).
+func (g *Game) Done() bool {
+	alive := len(g.Alive())
+	return g.Turn >= g.MaxTurns || alive == 0 || (alive == 1 && len(g.bots) > 1)
+}
+
+// Step plays a single turn, returning false if the game was already done
+func (g *Game) Step() bool {
+	if g.Done() {
+		return false
+	}
+	g.Turn++
+	moves := make(map[int]hlt.MoveSet)
+	for _, owner := range g.Alive() {
+		moves[owner] = g.moves(owner)
+	}
+	next := Resolve(g.Map, moves)
+	for _, observer := range g.Observers {
+		observer(g.Turn, g.Map, moves, next)
+	}
+	g.Map = next
+	for _, owner := range g.Alive() {
+		g.results[owner-1].LastTurnAlive = g.Turn
+	}
+	return true
+}
+
+// Run plays turns until the game is done, then closes any bots that are io.Closers
+func (g *Game) Run() Result {
+	for g.Step() {
+	}
+	g.Close()
+	return g.Result()
+}
+
+// Close releases any bots that are io.Closers, such as a Process
+func (g *Game) Close() {
+	for _, bot := range g.bots {
+		closeBot(bot)
+	}
+}
+
+func closeBot(bot Bot) {
+	if closer, ok := bot.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// Result ranks the players. Players eliminated earlier rank lower, survivors
+// are ranked by territory and then strength.
+func (g *Game) Result() Result {
+	territories := Territories(g.Map)
+	results := make([]PlayerResult, len(g.results))
+	copy(results, g.results)
+	for i := range results {
+		results[i].Territory = territories[i+1]
+		results[i].Production = 0
+		results[i].Strength = 0
+	}
+	for y := range g.Map.Contents {
+		for _, site := range g.Map.Contents[y] {
+			if site.Owner != 0 && site.Owner <= len(results) {
+				results[site.Owner-1].Production += site.Production
+				results[site.Owner-1].Strength += site.Strength
+			}
+		}
+	}
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := results[order[i]], results[order[j]]
+		if a.LastTurnAlive != b.LastTurnAlive {
+			return a.LastTurnAlive > b.LastTurnAlive
+		}
+		if a.Territory != b.Territory {
+			return a.Territory > b.Territory
+		}
+		return a.Strength > b.Strength
+	})
+	for rank, i := range order {
+		results[i].Rank = rank + 1
+	}
+	return Result{Turns: g.Turn, Players: results}
+}
+
+// Play is a convenience for NewGame followed by Run
+func Play(config Config, bots ...NewBot) (Result, error) {
+	game, err := NewGame(config, bots...)
+	if err != nil {
+		return Result{}, err
+	}
+	return game.Run(), nil
+}
@@ -0,0 +1,251 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"halite/hlt"
+)
+
+func setSite(owner, production, strength int, site *hlt.Site) {
+	site.Owner = owner
+	site.Production = production
+	site.Strength = strength
+}
+
+func mockGameMap(production, strength, width, height int) hlt.GameMap {
+	m := hlt.NewGameMap(width, height)
+	for y := range m.Contents {
+		for x := range m.Contents[y] {
+			setSite(0, production, strength, &m.Contents[y][x])
+		}
+	}
+	return m
+}
+
+func move(x, y int, direction hlt.Direction) hlt.Move {
+	return hlt.Move{Location: hlt.NewLocation(x, y), Direction: direction}
+}
+
+// greedyBot attacks its weakest beatable neighbor and otherwise waits
+type greedyBot struct {
+	owner   int
+	gameMap hlt.GameMap
+}
+
+func newGreedyBot(owner int, gameMap hlt.GameMap) Bot {
+	return &greedyBot{owner: owner, gameMap: gameMap}
+}
+
+func (b *greedyBot) Update(gameMap hlt.GameMap) {
+	b.gameMap = gameMap
+}
+
+func (b *greedyBot) Moves() hlt.MoveSet {
+	moves := hlt.MoveSet{}
+	for y := range b.gameMap.Contents {
+		for x, site := range b.gameMap.Contents[y] {
+			if site.Owner != b.owner {
+				continue
+			}
+			location := hlt.NewLocation(x, y)
+			direction := hlt.STILL
+			for _, d := range hlt.CARDINALS {
+				other := b.gameMap.GetSite(location, d)
+				if other.Owner != b.owner && other.Strength < site.Strength {
+					direction = d
+					break
+				}
+			}
+			if direction == hlt.STILL && site.Strength > site.Production*5 {
+				direction = hlt.CARDINALS[(x+y)%4]
+			}
+			moves = append(moves, hlt.Move{Location: location, Direction: direction})
+		}
+	}
+	return moves
+}
+
+type panicBot struct{}
+
+func (panicBot) Update(gameMap hlt.GameMap) {}
+func (panicBot) Moves() hlt.MoveSet         { panic("boom") }
+
+func TestResolveProductionCap(t *testing.T) {
+	m := mockGameMap(10, 0, 3, 3)
+	setSite(1, 10, 250, &m.Contents[0][0])
+	setSite(1, 10, 20, &m.Contents[0][1])
+	next := Resolve(m, map[int]hlt.MoveSet{1: {move(1, 0, hlt.STILL)}})
+	if next.Contents[0][0].Strength != 255 || next.Contents[0][1].Strength != 30 {
+		fmt.Println(next.Contents[0])
+		t.Fail()
+	}
+	if m.Contents[0][0].Strength != 250 {
+		fmt.Println("Resolve should not modify the given map")
+		t.Fail()
+	}
+}
+
+func TestResolveMergeCap(t *testing.T) {
+	m := mockGameMap(1, 0, 3, 3)
+	setSite(1, 1, 200, &m.Contents[1][0])
+	setSite(1, 1, 100, &m.Contents[1][2])
+	setSite(1, 1, 50, &m.Contents[1][1])
+	next := Resolve(m, map[int]hlt.MoveSet{1: {move(0, 1, hlt.EAST), move(2, 1, hlt.WEST)}})
+	// 200 + 100 + (50 + 1 production) capped at 255, the moved-from sites are kept at 0
+	if next.Contents[1][1] != (hlt.Site{Owner: 1, Production: 1, Strength: 255}) {
+		fmt.Println(next.Contents[1][1])
+		t.Fail()
+	}
+	if next.Contents[1][0] != (hlt.Site{Owner: 1, Production: 1, Strength: 0}) {
+		fmt.Println(next.Contents[1][0])
+		t.Fail()
+	}
+}
+
+func TestResolveNeutralCapture(t *testing.T) {
+	m := mockGameMap(1, 10, 3, 1)
+	setSite(1, 1, 15, &m.Contents[0][0])
+	next := Resolve(m, map[int]hlt.MoveSet{1: {move(0, 0, hlt.EAST)}})
+	if next.Contents[0][1] != (hlt.Site{Owner: 1, Production: 1, Strength: 5}) {
+		fmt.Println(next.Contents[0][1])
+		t.Fail()
+	}
+	// equal strength leaves the site unowned and empty
+	m = mockGameMap(1, 15, 3, 1)
+	setSite(1, 1, 15, &m.Contents[0][0])
+	next = Resolve(m, map[int]hlt.MoveSet{1: {move(0, 0, hlt.EAST)}})
+	if next.Contents[0][1] != (hlt.Site{Owner: 0, Production: 1, Strength: 0}) {
+		fmt.Println(next.Contents[0][1])
+		t.Fail()
+	}
+}
+
+func TestResolveOverkill(t *testing.T) {
+	m := mockGameMap(0, 0, 5, 5)
+	setSite(1, 0, 100, &m.Contents[2][1])
+	setSite(2, 0, 30, &m.Contents[1][2])
+	setSite(2, 0, 30, &m.Contents[3][2])
+	setSite(2, 0, 30, &m.Contents[2][3])
+	// player 1 moves into the middle, dealing 100 to each of the three adjacent enemy pieces
+	next := Resolve(m, map[int]hlt.MoveSet{1: {move(1, 2, hlt.EAST)}})
+	for _, location := range []hlt.Location{{X: 2, Y: 1}, {X: 2, Y: 3}, {X: 3, Y: 2}} {
+		if next.Contents[location.Y][location.X].Owner != 0 {
+			fmt.Println(location, next.Contents[location.Y][location.X])
+			t.Fail()
+		}
+	}
+	if next.Contents[2][2] != (hlt.Site{Owner: 1, Production: 0, Strength: 10}) {
+		fmt.Println(next.Contents[2][2])
+		t.Fail()
+	}
+	// the zero strength piece left behind is out of reach of the enemy
+	if next.Contents[2][1].Owner != 1 {
+		fmt.Println(next.Contents[2][1])
+		t.Fail()
+	}
+}
+
+func TestResolveNeutralWall(t *testing.T) {
+	m := mockGameMap(0, 5, 4, 1)
+	setSite(1, 0, 50, &m.Contents[0][0])
+	setSite(2, 0, 50, &m.Contents[0][2])
+	// both stand still with a neutral site between them, no damage is dealt
+	next := Resolve(m, map[int]hlt.MoveSet{})
+	if next.Contents[0][0].Strength != 50 || next.Contents[0][2].Strength != 50 {
+		fmt.Println(next.Contents[0])
+		t.Fail()
+	}
+}
+
+func TestGenerateMapSymmetric(t *testing.T) {
+	m := GenerateMap(20, 10, 2, 7)
+	if m.Width != 20 || m.Height != 10 {
+		fmt.Println(m.Width, m.Height)
+		t.Fail()
+	}
+	for y := range m.Contents {
+		for x := 0; x < 10; x++ {
+			a, b := m.Contents[y][x], m.Contents[y][x+10]
+			if a.Production != b.Production || a.Strength != b.Strength {
+				fmt.Println(x, y, a, b)
+				t.Fail()
+			}
+		}
+	}
+	territories := Territories(m)
+	if territories[1] != 1 || territories[2] != 1 {
+		fmt.Println(territories)
+		t.Fail()
+	}
+}
+
+func TestPlay(t *testing.T) {
+	result, err := Play(Config{Width: 20, Height: 20, Seed: 1}, newGreedyBot, newGreedyBot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Turns == 0 || result.Turns > 200 {
+		fmt.Println("Turns:", result.Turns)
+		t.Fail()
+	}
+	if result.Players[0].Territory <= 1 || result.Players[1].Territory <= 1 {
+		fmt.Println(result.Players)
+		t.Fail()
+	}
+	ranks := map[int]bool{result.Players[0].Rank: true, result.Players[1].Rank: true}
+	if !ranks[1] || !ranks[2] {
+		fmt.Println(result.Players)
+		t.Fail()
+	}
+}
+
+func TestPlayEliminatesPanickingBot(t *testing.T) {
+	result, err := Play(Config{Width: 10, Height: 10, Seed: 1}, newGreedyBot, func(owner int, gameMap hlt.GameMap) Bot {
+		return panicBot{}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Turns != 1 || result.Players[1].Err == nil || result.Players[0].Rank != 1 {
+		fmt.Println(result)
+		t.Fail()
+	}
+}
+
+// TestMain lets the test binary stand in for a bot program when run by TestProcess
+func TestMain(m *testing.M) {
+	if os.Getenv("ENGINE_TEST_BOT") == "1" {
+		conn, gameMap, err := hlt.Open(os.Stdin, os.Stdout)
+		if err != nil {
+			os.Exit(1)
+		}
+		bot := newGreedyBot(conn.PlayerTag, gameMap)
+		conn.SendName("greedy")
+		for {
+			gameMap, err := conn.ReadFrame()
+			if err != nil {
+				os.Exit(0)
+			}
+			bot.Update(gameMap)
+			conn.SendFrame(bot.Moves())
+		}
+	}
+	os.Exit(m.Run())
+}
+
+func TestProcess(t *testing.T) {
+	t.Setenv("ENGINE_TEST_BOT", "1")
+	result, err := Play(Config{Width: 10, Height: 10, Seed: 1, MaxTurns: 30}, NewProcessBot([]string{os.Args[0]}), newGreedyBot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the bot plays the same game out of process as it does in process
+	expected, _ := Play(Config{Width: 10, Height: 10, Seed: 1, MaxTurns: 30}, newGreedyBot, newGreedyBot)
+	if result.Players[0].Err != nil || fmt.Sprint(result) != fmt.Sprint(expected) {
+		fmt.Println(result)
+		fmt.Println(expected)
+		t.Fail()
+	}
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"math"
+	"math/rand"
+
+	"halite/hlt"
+)
+
+const maxProduction = 15
+const smoothPasses = 3
+
+// GenerateMap produces a map for the given number of players. Like the maps
+// served by halite.io it is made from one randomly generated tile repeated so
+// that every player has an identical neighbourhood, with each player starting
+// on a single site at the center of its tile. The map shrinks to the nearest
+// multiple of the tile grid when width or height do not divide evenly.
+func GenerateMap(width int, height int, players int, seed int64) hlt.GameMap {
+	random := rand.New(rand.NewSource(seed))
+	columns, rows := tileGrid(players)
+	tileWidth := max(1, width/columns)
+	tileHeight := max(1, height/rows)
+
+	richness := smooth(noise(random, tileWidth, tileHeight))
+	hardness := smooth(noise(random, tileWidth, tileHeight))
+
+	gameMap := hlt.NewGameMap(tileWidth*columns, tileHeight*rows)
+	for y := range gameMap.Contents {
+		for x := range gameMap.Contents[y] {
+			r := richness[y%tileHeight][x%tileWidth]
+			h := hardness[y%tileHeight][x%tileWidth]
+			gameMap.Contents[y][x] = hlt.Site{
+				Owner:      0,
+				Production: int(math.Round(r * maxProduction)),
+				Strength:   int(math.Round((0.5*r + 0.5*h) * maxStrength)),
+			}
+		}
+	}
+	for player := 0; player < players; player++ {
+		x := (player%columns)*tileWidth + tileWidth/2
+		y := (player/columns)*tileHeight + tileHeight/2
+		gameMap.Contents[y][x].Owner = player + 1
+	}
+	return gameMap
+}
+
+// tileGrid picks the most square columns x rows grid holding exactly n tiles
+func tileGrid(n int) (int, int) {
+	rows := int(math.Sqrt(float64(n)))
+	for n%rows != 0 {
+		rows--
+	}
+	return n / rows, rows
+}
+
+func noise(random *rand.Rand, width int, height int) [][]float64 {
+	grid := make([][]float64, height)
+	for y := range grid {
+		grid[y] = make([]float64, width)
+		for x := range grid[y] {
+			grid[y][x] = random.Float64()
+		}
+	}
+	return grid
+}
+
+// smooth blurs the grid (wrapping at its edges so tiles join seamlessly) and
+// stretches the result back over [0, 1].
+func smooth(grid [][]float64) [][]float64 {
+	height := len(grid)
+	width := len(grid[0])
+	for pass := 0; pass < smoothPasses; pass++ {
+		blurred := make([][]float64, height)
+		for y := range grid {
+			blurred[y] = make([]float64, width)
+			for x := range grid[y] {
+				sum := 0.0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						sum += grid[(y+dy+height)%height][(x+dx+width)%width]
+					}
+				}
+				blurred[y][x] = sum / 9
+			}
+		}
+		grid = blurred
+	}
+	low, high := math.Inf(1), math.Inf(-1)
+	for y := range grid {
+		for _, value := range grid[y] {
+			low = math.Min(low, value)
+			high = math.Max(high, value)
+		}
+	}
+	for y := range grid {
+		for x := range grid[y] {
+			if high > low {
+				grid[y][x] = (grid[y][x] - low) / (high - low)
+			} else {
+				grid[y][x] = 0.5
+			}
+		}
+	}
+	return grid
+}
@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"halite/hlt"
+)
+
+// Timeouts the Halite environment gives bots to initialize and to answer each frame
+const DefaultInitTimeout = 15 * time.Second
+const DefaultTurnTimeout = time.Second
+
+// Process is a Bot running as a separate program that speaks the hlt protocol
+// over its stdin and stdout, the way the Halite environment runs bots.
+type Process struct {
+	Name        string
+	TurnTimeout time.Duration
+	cmd         *exec.Cmd
+	stdin       io.WriteCloser
+	lines       chan string
+	err         error
+}
+
+// StartProcess runs command as player owner, sends it the initial map and waits
+// for its name.
+func StartProcess(command []string, owner int, gameMap hlt.GameMap, initTimeout time.Duration) (*Process, error) {
+	if len(command) == 0 {
+		return nil, errors.New("engine: empty bot command")
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &Process{
+		Name:        command[0],
+		TurnTimeout: DefaultTurnTimeout,
+		cmd:         cmd,
+		stdin:       stdin,
+		lines:       make(chan string),
+	}
+	go p.scan(stdout)
+	init := fmt.Sprintf("%d\n%d %d\n%s\n%s\n", owner, gameMap.Width, gameMap.Height, hlt.SerializeProductions(gameMap), hlt.SerializeMap(gameMap))
+	if _, err := io.WriteString(stdin, init); err != nil {
+		p.Close()
+		return nil, err
+	}
+	if p.Name, err = p.readLine(initTimeout); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewProcessBot is a NewBot that starts command for each player. Failures panic,
+// which eliminates the player.
+func NewProcessBot(command []string) NewBot {
+	return func(owner int, gameMap hlt.GameMap) Bot {
+		p, err := StartProcess(command, owner, gameMap, DefaultInitTimeout)
+		if err != nil {
+			panic(err)
+		}
+		return p
+	}
+}
+
+// scan forwards lines from stdout until it closes
+func (p *Process) scan(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		p.lines <- scanner.Text()
+	}
+	p.err = scanner.Err()
+	if p.err == nil {
+		p.err = io.EOF
+	}
+	close(p.lines)
+}
+
+func (p *Process) readLine(timeout time.Duration) (string, error) {
+	select {
+	case line, ok := <-p.lines:
+		if !ok {
+			return "", p.err
+		}
+		return line, nil
+	case <-time.After(timeout):
+		return "", fmt.Errorf("engine: %s timed out after %v", p.Name, timeout)
+	}
+}
+
+// Update sends the frame to the process
+func (p *Process) Update(gameMap hlt.GameMap) {
+	if _, err := io.WriteString(p.stdin, hlt.SerializeMap(gameMap)+"\n"); err != nil {
+		panic(err)
+	}
+}
+
+// Moves waits up to TurnTimeout for the process to answer the last frame
+func (p *Process) Moves() hlt.MoveSet {
+	line, err := p.readLine(p.TurnTimeout)
+	if err != nil {
+		panic(err)
+	}
+	moves, err := hlt.DeserializeMoveSet(line)
+	if err != nil {
+		panic(err)
+	}
+	return moves
+}
+
+// Close stops the process
+func (p *Process) Close() error {
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+	go func() {
+		// drain output so the scanner can finish
+		for range p.lines {
+		}
+	}()
+	p.cmd.Wait()
+	return nil
+}
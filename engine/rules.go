@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"halite/hlt"
+)
+
+const maxStrength = 255
+
+// pieces are the strengths held by one player, keyed by location
+type pieces map[hlt.Location]int
+
+func (p pieces) add(location hlt.Location, strength int) {
+	p[location] = min(maxStrength, p[location]+strength)
+}
+
+// Resolve applies one turn of moves to gameMap following the rules of the Halite
+// environment and returns the resulting map. moves are keyed by player tag. gameMap
+// is not modified.
+//
+// In order: pieces that stay STILL (or are given no move) gain their production,
+// every piece moves and merges with friendly pieces at its destination (capped at
+// 255), a moving piece leaves a zero strength piece behind so its owner keeps the
+// site, then every piece deals its strength as damage to each enemy piece on its
+// own and orthogonally adjacent sites, and to an unowned site it shares. Damage is
+// dealt simultaneously from pre-combat strengths. Pieces taking damage greater
+// than or equal to their strength are destroyed. Unowned sites never deal damage
+// to adjacent pieces.
+func Resolve(gameMap hlt.GameMap, moves map[int]hlt.MoveSet) hlt.GameMap {
+	next := gameMap.Clone()
+	byOwner := make(map[int]pieces)
+	owned := func(owner int) pieces {
+		if _, ok := byOwner[owner]; !ok {
+			byOwner[owner] = make(pieces)
+		}
+		return byOwner[owner]
+	}
+	// moved pieces, a piece can only be moved once and only by its owner
+	for owner, ownerMoves := range moves {
+		for _, move := range ownerMoves {
+			if !next.InBounds(move.Location) {
+				continue
+			}
+			site := &next.Contents[move.Location.Y][move.Location.X]
+			if owner == 0 || site.Owner != owner {
+				continue
+			}
+			strength := site.Strength
+			if move.Direction == hlt.STILL {
+				strength = min(maxStrength, strength+site.Production)
+			}
+			ownerPieces := owned(owner)
+			ownerPieces.add(next.GetLocation(move.Location, move.Direction), strength)
+			ownerPieces.add(move.Location, 0)
+			site.Owner = 0
+			site.Strength = 0
+		}
+	}
+	// remaining pieces stay still and produce
+	for y := range next.Contents {
+		for x := range next.Contents[y] {
+			site := &next.Contents[y][x]
+			if site.Owner != 0 {
+				owned(site.Owner).add(hlt.NewLocation(x, y), min(maxStrength, site.Strength+site.Production))
+				site.Owner = 0
+				site.Strength = 0
+			}
+		}
+	}
+	// sum damage from pre-combat strengths
+	injuries := make(map[int]pieces)
+	neutralDamage := make(map[hlt.Location]int)
+	for owner, ownerPieces := range byOwner {
+		for location, strength := range ownerPieces {
+			for otherOwner, otherPieces := range byOwner {
+				if otherOwner == owner {
+					continue
+				}
+				for _, direction := range hlt.Directions {
+					target := next.GetLocation(location, direction)
+					if _, ok := otherPieces[target]; ok {
+						if _, ok := injuries[otherOwner]; !ok {
+							injuries[otherOwner] = make(pieces)
+						}
+						injuries[otherOwner][target] += strength
+					}
+				}
+			}
+			if neutral := next.Contents[location.Y][location.X].Strength; neutral > 0 {
+				if _, ok := injuries[owner]; !ok {
+					injuries[owner] = make(pieces)
+				}
+				injuries[owner][location] += neutral
+				neutralDamage[location] += strength
+			}
+		}
+	}
+	// apply damage, pieces with damage >= strength are destroyed (including 0 vs 0)
+	for owner, ownerInjuries := range injuries {
+		for location, damage := range ownerInjuries {
+			if damage >= byOwner[owner][location] {
+				delete(byOwner[owner], location)
+			} else {
+				byOwner[owner][location] -= damage
+			}
+		}
+	}
+	for location, damage := range neutralDamage {
+		site := &next.Contents[location.Y][location.X]
+		site.Strength = max(0, site.Strength-damage)
+	}
+	// surviving pieces take their sites
+	for owner, ownerPieces := range byOwner {
+		for location, strength := range ownerPieces {
+			site := &next.Contents[location.Y][location.X]
+			site.Owner = owner
+			site.Strength = strength
+		}
+	}
+	return next
+}
+
+// Territories counts the sites held by each player tag
+func Territories(gameMap hlt.GameMap) map[int]int {
+	territories := make(map[int]int)
+	for y := range gameMap.Contents {
+		for _, site := range gameMap.Contents[y] {
+			if site.Owner != 0 {
+				territories[site.Owner]++
+			}
+		}
+	}
+	return territories
+}
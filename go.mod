@@ -0,0 +1,3 @@
+module halite
+
+go 1.21
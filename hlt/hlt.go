@@ -0,0 +1,158 @@
+package hlt
+
+import (
+	"math"
+)
+
+// Direction is one of the five moves a piece can make in a turn
+type Direction int
+
+// Directions as they are encoded by the Halite environment
+const (
+	STILL Direction = iota
+	NORTH
+	EAST
+	SOUTH
+	WEST
+)
+
+// Directions is every Direction, including STILL
+var Directions = []Direction{STILL, NORTH, EAST, SOUTH, WEST}
+
+// CARDINALS is every Direction that results in movement
+var CARDINALS = []Direction{NORTH, EAST, SOUTH, WEST}
+
+// Location is an x, y coordinate on the GameMap
+type Location struct {
+	X int
+	Y int
+}
+
+// NewLocation is a constructor
+func NewLocation(x int, y int) Location {
+	return Location{X: x, Y: y}
+}
+
+// Site is the state of a single Location on the GameMap
+type Site struct {
+	Owner      int
+	Strength   int
+	Production int
+}
+
+// Move is a Direction given to the piece at a Location
+type Move struct {
+	Location  Location
+	Direction Direction
+}
+
+// MoveSet is the list of Moves sent to the environment in a single frame
+type MoveSet []Move
+
+// GameMap is a toroidal grid of Sites, indexed as Contents[y][x]
+type GameMap struct {
+	Width    int
+	Height   int
+	Contents [][]Site
+}
+
+// NewGameMap is a constructor
+func NewGameMap(width int, height int) GameMap {
+	contents := make([][]Site, height)
+	for y := range contents {
+		contents[y] = make([]Site, width)
+	}
+	return GameMap{
+		Width:    width,
+		Height:   height,
+		Contents: contents,
+	}
+}
+
+// Clone produces a copy of the GameMap that shares no Sites with the original
+func (m GameMap) Clone() GameMap {
+	clone := NewGameMap(m.Width, m.Height)
+	for y := range m.Contents {
+		copy(clone.Contents[y], m.Contents[y])
+	}
+	return clone
+}
+
+// InBounds is true if the location is within the map without wrapping
+func (m GameMap) InBounds(location Location) bool {
+	return location.X >= 0 && location.X < m.Width && location.Y >= 0 && location.Y < m.Height
+}
+
+// GetDistance is the Manhattan distance between two locations, taking wrapping into account
+func (m GameMap) GetDistance(from Location, to Location) int {
+	dx := abs(from.X - to.X)
+	dy := abs(from.Y - to.Y)
+	if dx > m.Width/2 {
+		dx = m.Width - dx
+	}
+	if dy > m.Height/2 {
+		dy = m.Height - dy
+	}
+	return dx + dy
+}
+
+// GetAngle is the angle in radians from one location to another, taking wrapping into account
+func (m GameMap) GetAngle(from Location, to Location) float64 {
+	dx := to.X - from.X
+	dy := to.Y - from.Y
+	if dx > m.Width-dx {
+		dx -= m.Width
+	} else if -dx > m.Width+dx {
+		dx += m.Width
+	}
+	if dy > m.Height-dy {
+		dy -= m.Height
+	} else if -dy > m.Height+dy {
+		dy += m.Height
+	}
+	return math.Atan2(float64(dy), float64(dx))
+}
+
+// GetLocation returns the Location one step in the given direction, wrapping around map edges
+func (m GameMap) GetLocation(location Location, direction Direction) Location {
+	switch direction {
+	case NORTH:
+		if location.Y == 0 {
+			location.Y = m.Height - 1
+		} else {
+			location.Y--
+		}
+	case EAST:
+		if location.X == m.Width-1 {
+			location.X = 0
+		} else {
+			location.X++
+		}
+	case SOUTH:
+		if location.Y == m.Height-1 {
+			location.Y = 0
+		} else {
+			location.Y++
+		}
+	case WEST:
+		if location.X == 0 {
+			location.X = m.Width - 1
+		} else {
+			location.X--
+		}
+	}
+	return location
+}
+
+// GetSite returns the Site one step in the given direction
+func (m GameMap) GetSite(location Location, direction Direction) Site {
+	location = m.GetLocation(location, direction)
+	return m.Contents[location.Y][location.X]
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
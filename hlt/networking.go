@@ -0,0 +1,250 @@
+package hlt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Connection speaks the Halite environment protocol over any reader/writer pair.
+// The environment sends a player tag, map dimensions, productions and an initial
+// map, after which each frame is a single run-length-encoded map line answered
+// by a single line of moves.
+type Connection struct {
+	PlayerTag   int
+	width       int
+	height      int
+	productions [][]int
+	reader      *bufio.Reader
+	writer      *bufio.Writer
+}
+
+// NewConnection is a constructor for a Connection over stdin and stdout, as used
+// by the Halite environment. Panics if the initial frames cannot be read.
+func NewConnection() (Connection, GameMap) {
+	conn, gameMap, err := Open(os.Stdin, os.Stdout)
+	if err != nil {
+		panic(err)
+	}
+	return conn, gameMap
+}
+
+// Open reads the initial frames from reader and returns a Connection that will
+// write to writer. Tests can drive a bot in-memory by handing Open a pipe.
+func Open(reader io.Reader, writer io.Writer) (Connection, GameMap, error) {
+	conn := Connection{
+		reader: bufio.NewReader(reader),
+		writer: bufio.NewWriter(writer),
+	}
+	tag, err := conn.readInts()
+	if err != nil {
+		return conn, GameMap{}, err
+	}
+	if len(tag) != 1 {
+		return conn, GameMap{}, fmt.Errorf("hlt: expected player tag, got %v", tag)
+	}
+	conn.PlayerTag = tag[0]
+	size, err := conn.readInts()
+	if err != nil {
+		return conn, GameMap{}, err
+	}
+	if len(size) != 2 || size[0] <= 0 || size[1] <= 0 {
+		return conn, GameMap{}, fmt.Errorf("hlt: expected map dimensions, got %v", size)
+	}
+	conn.width, conn.height = size[0], size[1]
+	productions, err := conn.readInts()
+	if err != nil {
+		return conn, GameMap{}, err
+	}
+	if conn.productions, err = DeserializeProductions(conn.width, conn.height, productions); err != nil {
+		return conn, GameMap{}, err
+	}
+	gameMap, err := conn.ReadFrame()
+	return conn, gameMap, err
+}
+
+// SendName sends the bot's name, completing initialization. Panics on failure.
+func (c *Connection) SendName(name string) {
+	if err := c.writeLine(name); err != nil {
+		panic(err)
+	}
+}
+
+// GetFrame reads the next frame from the environment. Panics on failure.
+func (c *Connection) GetFrame() GameMap {
+	gameMap, err := c.ReadFrame()
+	if err != nil {
+		panic(err)
+	}
+	return gameMap
+}
+
+// SendFrame sends the moves for this frame to the environment. Panics on failure.
+func (c *Connection) SendFrame(moves MoveSet) {
+	if err := c.WriteFrame(moves); err != nil {
+		panic(err)
+	}
+}
+
+// ReadFrame reads the next frame from the environment. io.EOF is returned once
+// the environment has closed the connection at the end of the game.
+func (c *Connection) ReadFrame() (GameMap, error) {
+	values, err := c.readInts()
+	if err != nil {
+		return GameMap{}, err
+	}
+	return DeserializeMap(c.width, c.height, c.productions, values)
+}
+
+// WriteFrame writes the moves for this frame to the environment.
+func (c *Connection) WriteFrame(moves MoveSet) error {
+	return c.writeLine(SerializeMoveSet(moves))
+}
+
+func (c *Connection) readInts() ([]int, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return nil, err
+	}
+	return parseInts(line)
+}
+
+func (c *Connection) writeLine(line string) error {
+	if _, err := c.writer.WriteString(line + "\n"); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+func parseInts(line string) ([]int, error) {
+	fields := strings.Fields(line)
+	values := make([]int, len(fields))
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("hlt: %v", err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// DeserializeProductions converts the row-major production line sent during
+// initialization into a [y][x] grid.
+func DeserializeProductions(width int, height int, values []int) ([][]int, error) {
+	if len(values) != width*height {
+		return nil, fmt.Errorf("hlt: expected %d productions, got %d", width*height, len(values))
+	}
+	productions := make([][]int, height)
+	for y := range productions {
+		productions[y] = values[y*width : (y+1)*width]
+	}
+	return productions, nil
+}
+
+// DeserializeMap decodes a frame: (counter, owner) pairs covering every site,
+// followed by one strength per site, all in row-major order.
+func DeserializeMap(width int, height int, productions [][]int, values []int) (GameMap, error) {
+	gameMap := NewGameMap(width, height)
+	total := width * height
+	i, site := 0, 0
+	for site < total {
+		if i+1 >= len(values) {
+			return gameMap, errors.New("hlt: frame ended before all owners were read")
+		}
+		counter, owner := values[i], values[i+1]
+		i += 2
+		if counter <= 0 || site+counter > total {
+			return gameMap, fmt.Errorf("hlt: bad owner run length %d", counter)
+		}
+		for end := site + counter; site < end; site++ {
+			gameMap.Contents[site/width][site%width].Owner = owner
+		}
+	}
+	if len(values)-i != total {
+		return gameMap, fmt.Errorf("hlt: expected %d strengths, got %d", total, len(values)-i)
+	}
+	for site = 0; site < total; site++ {
+		x, y := site%width, site/width
+		gameMap.Contents[y][x].Strength = values[i+site]
+		gameMap.Contents[y][x].Production = productions[y][x]
+	}
+	return gameMap, nil
+}
+
+// SerializeProductions encodes the production of every site as sent during initialization.
+func SerializeProductions(gameMap GameMap) string {
+	var buffer bytes.Buffer
+	for y := 0; y < gameMap.Height; y++ {
+		for x := 0; x < gameMap.Width; x++ {
+			if buffer.Len() > 0 {
+				buffer.WriteString(" ")
+			}
+			buffer.WriteString(strconv.Itoa(gameMap.Contents[y][x].Production))
+		}
+	}
+	return buffer.String()
+}
+
+// SerializeMap encodes the owners and strengths of every site as sent each frame.
+func SerializeMap(gameMap GameMap) string {
+	var buffer bytes.Buffer
+	counter := 0
+	owner := 0
+	for y := 0; y < gameMap.Height; y++ {
+		for x := 0; x < gameMap.Width; x++ {
+			site := gameMap.Contents[y][x]
+			if counter > 0 && site.Owner != owner {
+				buffer.WriteString(fmt.Sprintf("%d %d ", counter, owner))
+				counter = 0
+			}
+			owner = site.Owner
+			counter++
+		}
+	}
+	buffer.WriteString(fmt.Sprintf("%d %d", counter, owner))
+	for y := 0; y < gameMap.Height; y++ {
+		for x := 0; x < gameMap.Width; x++ {
+			buffer.WriteString(" ")
+			buffer.WriteString(strconv.Itoa(gameMap.Contents[y][x].Strength))
+		}
+	}
+	return buffer.String()
+}
+
+// SerializeMoveSet encodes moves as "x y direction" triplets.
+func SerializeMoveSet(moves MoveSet) string {
+	var buffer bytes.Buffer
+	for i, move := range moves {
+		if i > 0 {
+			buffer.WriteString(" ")
+		}
+		buffer.WriteString(fmt.Sprintf("%d %d %d", move.Location.X, move.Location.Y, int(move.Direction)))
+	}
+	return buffer.String()
+}
+
+// DeserializeMoveSet decodes a line of "x y direction" triplets.
+func DeserializeMoveSet(line string) (MoveSet, error) {
+	values, err := parseInts(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(values)%3 != 0 {
+		return nil, fmt.Errorf("hlt: moves must be x y direction triplets, got %d values", len(values))
+	}
+	moves := make(MoveSet, 0, len(values)/3)
+	for i := 0; i < len(values); i += 3 {
+		direction := Direction(values[i+2])
+		if direction < STILL || direction > WEST {
+			return nil, fmt.Errorf("hlt: bad direction %d", values[i+2])
+		}
+		moves = append(moves, Move{Location: NewLocation(values[i], values[i+1]), Direction: direction})
+	}
+	return moves, nil
+}
@@ -0,0 +1,131 @@
+package hlt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func mockGameMap() GameMap {
+	m := NewGameMap(3, 2)
+	m.Contents[0][0] = Site{Owner: 1, Strength: 10, Production: 1}
+	m.Contents[0][1] = Site{Owner: 1, Strength: 20, Production: 2}
+	m.Contents[0][2] = Site{Owner: 0, Strength: 30, Production: 3}
+	m.Contents[1][0] = Site{Owner: 0, Strength: 40, Production: 4}
+	m.Contents[1][1] = Site{Owner: 2, Strength: 50, Production: 5}
+	m.Contents[1][2] = Site{Owner: 2, Strength: 60, Production: 6}
+	return m
+}
+
+func TestGetLocationWraps(t *testing.T) {
+	m := NewGameMap(5, 5)
+	if loc := m.GetLocation(NewLocation(0, 0), NORTH); loc != NewLocation(0, 4) {
+		fmt.Println(loc)
+		t.Fail()
+	}
+	if loc := m.GetLocation(NewLocation(4, 2), EAST); loc != NewLocation(0, 2) {
+		fmt.Println(loc)
+		t.Fail()
+	}
+	if distance := m.GetDistance(NewLocation(0, 0), NewLocation(4, 4)); distance != 2 {
+		fmt.Println(distance)
+		t.Fail()
+	}
+}
+
+func TestSerializeMap(t *testing.T) {
+	m := mockGameMap()
+	if SerializeMap(m) != "2 1 2 0 2 2 10 20 30 40 50 60" {
+		fmt.Println(SerializeMap(m))
+		t.Fail()
+	}
+	if SerializeProductions(m) != "1 2 3 4 5 6" {
+		fmt.Println(SerializeProductions(m))
+		t.Fail()
+	}
+}
+
+func TestMoveSetRoundTrip(t *testing.T) {
+	moves := MoveSet{
+		Move{Location: NewLocation(0, 1), Direction: NORTH},
+		Move{Location: NewLocation(2, 0), Direction: STILL},
+	}
+	line := SerializeMoveSet(moves)
+	if line != "0 1 1 2 0 0" {
+		fmt.Println(line)
+		t.Fail()
+	}
+	decoded, err := DeserializeMoveSet(line)
+	if err != nil || len(decoded) != 2 || decoded[0] != moves[0] || decoded[1] != moves[1] {
+		fmt.Println(decoded, err)
+		t.Fail()
+	}
+	if _, err := DeserializeMoveSet("0 1 7"); err == nil {
+		fmt.Println("Direction 7 should be rejected")
+		t.Fail()
+	}
+}
+
+func TestConnectionOverPipes(t *testing.T) {
+	m := mockGameMap()
+	envReader, botWriter := io.Pipe()
+	botReader, envWriter := io.Pipe()
+
+	go func() {
+		fmt.Fprintf(envWriter, "2\n%d %d\n%s\n%s\n", m.Width, m.Height, SerializeProductions(m), SerializeMap(m))
+		m.Contents[1][0].Owner = 2
+		fmt.Fprintf(envWriter, "%s\n", SerializeMap(m))
+		envWriter.Close()
+	}()
+
+	replies := make(chan string, 2)
+	go func() {
+		scanner := bufio.NewScanner(envReader)
+		for scanner.Scan() {
+			replies <- scanner.Text()
+		}
+		close(replies)
+	}()
+
+	conn, gameMap, err := Open(botReader, botWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.PlayerTag != 2 || gameMap.Contents[1][2] != (Site{Owner: 2, Strength: 60, Production: 6}) {
+		fmt.Println(conn.PlayerTag, gameMap.Contents)
+		t.Fail()
+	}
+	conn.SendName("TestBot")
+	if name := <-replies; name != "TestBot" {
+		fmt.Println(name)
+		t.Fail()
+	}
+
+	gameMap = conn.GetFrame()
+	if gameMap.Contents[1][0] != (Site{Owner: 2, Strength: 40, Production: 4}) {
+		fmt.Println(gameMap.Contents)
+		t.Fail()
+	}
+	conn.SendFrame(MoveSet{Move{Location: NewLocation(1, 1), Direction: WEST}})
+	if line := <-replies; line != "1 1 4" {
+		fmt.Println(line)
+		t.Fail()
+	}
+
+	if _, err := conn.ReadFrame(); err != io.EOF {
+		fmt.Println("Expected EOF once the environment closes, got", err)
+		t.Fail()
+	}
+	botWriter.Close()
+}
+
+func TestDeserializeMapRejectsShortFrames(t *testing.T) {
+	productions, _ := DeserializeProductions(3, 2, []int{1, 2, 3, 4, 5, 6})
+	values, _ := parseInts(strings.Repeat("1 ", 10))
+	if _, err := DeserializeMap(3, 2, productions, values); err == nil {
+		fmt.Println("Expected an error for a truncated frame")
+		t.Fail()
+	}
+}
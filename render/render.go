@@ -0,0 +1,232 @@
+// Package render draws a bot's flow field over a Halite map. A Grid is one turn
+// of a field with the ownership and production under it, built by FlowGrid for
+// cmd/flowdump and cmd/viewer, so the competition bot never links image encoding.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+
+	"halite/bot"
+	"halite/hlt"
+)
+
+// CellSize is the width and height in pixels of a cell in an Image or SVG
+const CellSize = 12
+
+// ownerColors are the colors of unowned cells and of each player, repeating after the last
+var ownerColors = []color.RGBA{
+	{R: 40, G: 40, B: 40, A: 255},
+	{R: 46, G: 134, B: 222, A: 255},
+	{R: 232, G: 65, B: 24, A: 255},
+	{R: 68, G: 189, B: 50, A: 255},
+	{R: 251, G: 197, B: 49, A: 255},
+	{R: 140, G: 122, B: 230, A: 255},
+	{R: 0, G: 168, B: 168, A: 255},
+}
+
+// OwnerColor is the color of an owner, 0 for unowned cells
+func OwnerColor(owner int) color.RGBA {
+	return ownerColors[owner%len(ownerColors)]
+}
+
+// Grid is a flow field on one turn. Every slice is indexed y*Width+x. Cells the
+// field does not reach have no Cost or Direction.
+type Grid struct {
+	Turn          int             `json:"turn"`
+	Width         int             `json:"width"`
+	Height        int             `json:"height"`
+	MaxProduction int             `json:"max_production"`
+	Owner         []int           `json:"owner"`
+	Production    []int           `json:"production"`
+	Reached       []bool          `json:"reached"`
+	Cost          []int           `json:"cost"`
+	Direction     []hlt.Direction `json:"direction"`
+	Destination   []bool          `json:"destination"`
+}
+
+// NewGrid is a constructor for a grid that reaches nothing yet
+func NewGrid(turn int, width int, height int) *Grid {
+	size := width * height
+	return &Grid{
+		Turn:        turn,
+		Width:       width,
+		Height:      height,
+		Owner:       make([]int, size),
+		Production:  make([]int, size),
+		Reached:     make([]bool, size),
+		Cost:        make([]int, size),
+		Direction:   make([]hlt.Direction, size),
+		Destination: make([]bool, size),
+	}
+}
+
+// FlowGrid is the field over the cells' ownership and production on a turn
+func FlowGrid(ff *bot.FlowField, cells *bot.Cells, turn int) *Grid {
+	grid := NewGrid(turn, cells.Width, cells.Height)
+	grid.MaxProduction = cells.MaxProduction
+	destinations := map[hlt.Location]bool{}
+	for _, cell := range ff.Destinations {
+		destinations[cell.Location] = true
+	}
+	for i := range cells.Contents {
+		cell := &cells.Contents[i]
+		grid.Owner[i] = cell.Owner
+		grid.Production[i] = cell.Production
+		if !ff.Reached(cell.Location) {
+			continue
+		}
+		grid.Reached[i] = true
+		grid.Cost[i] = ff.Cost(cell.Location)
+		grid.Direction[i] = ff.Direction(cell.Location)
+		grid.Destination[i] = destinations[cell.Location]
+	}
+	return grid
+}
+
+// CostRange is the lowest and highest cost the grid reached, high is below low
+// when it reached nothing
+func (g *Grid) CostRange() (int, int) {
+	low, high := 0, -1
+	first := true
+	for i, reached := range g.Reached {
+		if !reached {
+			continue
+		}
+		if first || g.Cost[i] < low {
+			low = g.Cost[i]
+		}
+		if first || g.Cost[i] > high {
+			high = g.Cost[i]
+		}
+		first = false
+	}
+	return low, high
+}
+
+// CellColor is the color of the cell at index i, its owner's color brighter for
+// more production and tinted along a blue to red ramp by its cost between low
+// and high when the grid reaches it
+func (g *Grid) CellColor(i int, low int, high int) color.RGBA {
+	base := OwnerColor(g.Owner[i])
+	// production lights the owner color from a third to full brightness
+	light := 1.0 / 3.0
+	if g.MaxProduction > 0 {
+		light += 2.0 / 3.0 * float64(g.Production[i]) / float64(g.MaxProduction)
+	}
+	c := color.RGBA{R: uint8(float64(base.R) * light), G: uint8(float64(base.G) * light), B: uint8(float64(base.B) * light), A: 255}
+	if !g.Reached[i] {
+		return c
+	}
+	ramp := 0.0
+	if high > low {
+		ramp = float64(g.Cost[i]-low) / float64(high-low)
+	}
+	cost := color.RGBA{R: uint8(255 * ramp), G: 64, B: uint8(255 * (1 - ramp)), A: 255}
+	return blend(c, cost, 0.5)
+}
+
+func blend(a color.RGBA, b color.RGBA, amount float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x)*(1-amount) + float64(y)*amount)
+	}
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
+
+// arrow is the line from the middle of a cell of size pixels towards its
+// direction, and the two strokes of its head, relative to the cell's top left corner
+func arrow(direction hlt.Direction, size int) [][4]int {
+	mid := size / 2
+	reach := size * 2 / 5
+	head := max(1, size/6)
+	dx, dy := 0, 0
+	switch direction {
+	case hlt.NORTH:
+		dy = -1
+	case hlt.EAST:
+		dx = 1
+	case hlt.SOUTH:
+		dy = 1
+	case hlt.WEST:
+		dx = -1
+	default:
+		return [][4]int{{mid, mid, mid, mid}}
+	}
+	tipX, tipY := mid+dx*reach, mid+dy*reach
+	return [][4]int{
+		{mid - dx*reach, mid - dy*reach, tipX, tipY},
+		{tipX, tipY, tipX - dx*head + dy*head, tipY - dy*head + dx*head},
+		{tipX, tipY, tipX - dx*head - dy*head, tipY - dy*head - dx*head},
+	}
+}
+
+// Image draws the grid with size pixels per cell. Reached cells are marked with
+// an arrow in their direction and destinations are outlined.
+func (g *Grid) Image(size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, g.Width*size, g.Height*size))
+	low, high := g.CostRange()
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	for i := range g.Owner {
+		left, top := (i%g.Width)*size, (i/g.Width)*size
+		draw.Draw(img, image.Rect(left, top, left+size, top+size), image.NewUniform(g.CellColor(i, low, high)), image.Point{}, draw.Src)
+		if !g.Reached[i] {
+			continue
+		}
+		if g.Destination[i] {
+			for j := 0; j < size; j++ {
+				img.SetRGBA(left+j, top, white)
+				img.SetRGBA(left+j, top+size-1, white)
+				img.SetRGBA(left, top+j, white)
+				img.SetRGBA(left+size-1, top+j, white)
+			}
+		}
+		for _, line := range arrow(g.Direction[i], size) {
+			drawLine(img, left+line[0], top+line[1], left+line[2], top+line[3], white)
+		}
+	}
+	return img
+}
+
+// drawLine plots a straight line between two points
+func drawLine(img *image.RGBA, x0 int, y0 int, x1 int, y1 int, c color.RGBA) {
+	steps := max(max(x1-x0, x0-x1), max(y1-y0, y0-y1))
+	if steps == 0 {
+		img.SetRGBA(x0, y0, c)
+		return
+	}
+	for i := 0; i <= steps; i++ {
+		img.SetRGBA(x0+(x1-x0)*i/steps, y0+(y1-y0)*i/steps, c)
+	}
+}
+
+// WriteSVG writes the grid as an SVG document with size pixels per cell, titling
+// each arrow with its cell and cost
+func (g *Grid) WriteSVG(writer io.Writer, size int) error {
+	low, high := g.CostRange()
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d">`+"\n", g.Width*size, g.Height*size)
+	for i := range g.Owner {
+		left, top := (i%g.Width)*size, (i/g.Width)*size
+		c := g.CellColor(i, low, high)
+		fmt.Fprintf(&buffer, `<rect x="%d" y="%d" width="%d" height="%d" fill="#%02x%02x%02x"`, left, top, size, size, c.R, c.G, c.B)
+		if g.Reached[i] && g.Destination[i] {
+			buffer.WriteString(` stroke="white"`)
+		}
+		buffer.WriteString("/>\n")
+		if !g.Reached[i] {
+			continue
+		}
+		buffer.WriteString(`<path stroke="white" fill="none" d="`)
+		for _, line := range arrow(g.Direction[i], size) {
+			fmt.Fprintf(&buffer, "M%d %dL%d %d", left+line[0], top+line[1], left+line[2], top+line[3])
+		}
+		fmt.Fprintf(&buffer, `"><title>(x:%d, y:%d) cost %d</title></path>`+"\n", i%g.Width, i/g.Width, g.Cost[i])
+	}
+	buffer.WriteString("</svg>\n")
+	_, err := writer.Write(buffer.Bytes())
+	return err
+}
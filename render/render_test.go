@@ -0,0 +1,150 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+
+	"halite/bot"
+	"halite/hlt"
+)
+
+// testGrid is a 3x2 map owned by player 1 on the left column and player 2 on
+// the right, with a field flowing east along the top row into a destination
+func testGrid() *Grid {
+	g := NewGrid(7, 3, 2)
+	g.MaxProduction = 4
+	g.Owner = []int{1, 0, 2, 1, 0, 2}
+	g.Production = []int{4, 2, 0, 1, 1, 1}
+	for i, cost := range []int{2, 1, 0} {
+		g.Reached[i] = true
+		g.Cost[i] = cost
+		g.Direction[i] = hlt.EAST
+	}
+	g.Direction[2] = hlt.STILL
+	g.Destination[2] = true
+	return g
+}
+
+func TestCellColor(t *testing.T) {
+	g := testGrid()
+	low, high := g.CostRange()
+	if low != 0 || high != 2 {
+		fmt.Println("Cost range:", low, high)
+		t.Fail()
+	}
+	if low, high := NewGrid(0, 2, 2).CostRange(); high >= low {
+		fmt.Println("Empty cost range:", low, high)
+		t.Fail()
+	}
+	// unreached cells keep their owner color, dimmed by low production
+	if c := g.CellColor(3, low, high); c.B >= OwnerColor(1).B || c.B <= OwnerColor(1).B/3 {
+		fmt.Println("Unreached color:", c)
+		t.Fail()
+	}
+	// the costliest reached cell leans red, the cheapest blue
+	costly, cheap := g.CellColor(0, low, high), g.CellColor(2, low, high)
+	if costly.R <= cheap.R || cheap.B <= costly.B {
+		fmt.Println("Cost ramp:", costly, cheap)
+		t.Fail()
+	}
+}
+
+func TestImage(t *testing.T) {
+	g := testGrid()
+	img := g.Image(CellSize)
+	if img.Bounds().Dx() != 3*CellSize || img.Bounds().Dy() != 2*CellSize {
+		fmt.Println("Image size:", img.Bounds())
+		t.Fail()
+	}
+	low, high := g.CostRange()
+	if color.RGBAModel.Convert(img.At(2, 1*CellSize+2)) != g.CellColor(3, low, high) {
+		fmt.Println("Unreached cell:", img.At(2, CellSize+2))
+		t.Fail()
+	}
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	if img.RGBAAt(2*CellSize, 0) != white {
+		fmt.Println("Destination should be outlined:", img.RGBAAt(2*CellSize, 0))
+		t.Fail()
+	}
+	// the arrow east runs through the middle of the cell
+	if img.RGBAAt(CellSize/2+2, CellSize/2) != white {
+		fmt.Println("Arrow missing:", img.RGBAAt(CellSize/2+2, CellSize/2))
+		t.Fail()
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := testGrid().WriteSVG(&buffer, CellSize); err != nil {
+		t.Fatal(err)
+	}
+	svg := buffer.String()
+	if strings.Count(svg, "<rect") != 6 || strings.Count(svg, "<path") != 3 || strings.Count(svg, `stroke="white"/>`) != 1 {
+		fmt.Println(svg)
+		t.Fail()
+	}
+	if !strings.Contains(svg, "<title>(x:0, y:0) cost 2</title>") {
+		fmt.Println(svg)
+		t.Fail()
+	}
+}
+
+func TestGridJSON(t *testing.T) {
+	g := testGrid()
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Grid
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*g, decoded) {
+		fmt.Println(string(data))
+		t.Fail()
+	}
+}
+
+func TestFlowGrid(t *testing.T) {
+	m := hlt.NewGameMap(6, 4)
+	for y := range m.Contents {
+		for x := range m.Contents[y] {
+			m.Contents[y][x] = hlt.Site{Production: 1, Strength: 20}
+		}
+	}
+	m.Contents[1][1] = hlt.Site{Owner: 1, Production: 3, Strength: 50}
+	m.Contents[1][2] = hlt.Site{Owner: 1, Production: 3, Strength: 50}
+	m.Contents[2][1] = hlt.Site{Owner: 1, Production: 2, Strength: 50}
+	m.Contents[2][4] = hlt.Site{Owner: 2, Production: 1, Strength: 50}
+	b := bot.NewBot(1, m)
+	b.Update(m)
+	grid := FlowGrid(b.BodyFlow, b.Cells, 3)
+	if grid.Turn != 3 || grid.Width != 6 || grid.Height != 4 || grid.MaxProduction != b.Cells.MaxProduction {
+		fmt.Println("Grid:", grid.Turn, grid.Width, grid.Height, grid.MaxProduction)
+		t.Fail()
+	}
+	destinations := 0
+	for i := range b.Cells.Contents {
+		cell := &b.Cells.Contents[i]
+		if grid.Owner[i] != cell.Owner || grid.Production[i] != cell.Production || grid.Reached[i] != b.BodyFlow.Reached(cell.Location) {
+			fmt.Println("Grid cell:", cell, grid.Owner[i], grid.Production[i], grid.Reached[i])
+			t.Fail()
+		}
+		if grid.Reached[i] && (grid.Cost[i] != b.BodyFlow.Cost(cell.Location) || grid.Direction[i] != b.BodyFlow.Direction(cell.Location)) {
+			fmt.Println("Grid flow:", cell, grid.Cost[i], grid.Direction[i])
+			t.Fail()
+		}
+		if grid.Destination[i] {
+			destinations++
+		}
+	}
+	if destinations == 0 || destinations != len(b.BodyFlow.Destinations) {
+		fmt.Println("Destinations:", destinations, "of", len(b.BodyFlow.Destinations))
+		t.Fail()
+	}
+}
@@ -0,0 +1,219 @@
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"halite/engine"
+	"halite/hlt"
+)
+
+// Version of the halite.io replay format written by this package
+const Version = 11
+
+// Replay is a recorded game in the halite.io .hlt JSON format. Frames and Moves
+// are indexed [turn][y][x]. Each frame site is an [owner, strength] pair and each
+// move is the direction given to the piece on that site, Moves[t] takes Frames[t]
+// to Frames[t+1].
+type Replay struct {
+	Version     int          `json:"version"`
+	Width       int          `json:"width"`
+	Height      int          `json:"height"`
+	NumPlayers  int          `json:"num_players"`
+	NumFrames   int          `json:"num_frames"`
+	PlayerNames []string     `json:"player_names"`
+	Productions [][]int      `json:"productions"`
+	Frames      [][][][2]int `json:"frames"`
+	Moves       [][][]int    `json:"moves"`
+}
+
+// New is a constructor for an empty Replay of the given initial map
+func New(gameMap hlt.GameMap, playerNames []string) *Replay {
+	productions := make([][]int, gameMap.Height)
+	for y := range productions {
+		productions[y] = make([]int, gameMap.Width)
+		for x := range productions[y] {
+			productions[y][x] = gameMap.Contents[y][x].Production
+		}
+	}
+	return &Replay{
+		Version:     Version,
+		Width:       gameMap.Width,
+		Height:      gameMap.Height,
+		NumPlayers:  len(playerNames),
+		NumFrames:   0,
+		PlayerNames: playerNames,
+		Productions: productions,
+		Frames:      make([][][][2]int, 0),
+		Moves:       make([][][]int, 0),
+	}
+}
+
+// Record attaches a new Replay to game, every turn played from now on is added to it.
+func Record(game *engine.Game, playerNames []string) *Replay {
+	r := New(game.Map, playerNames)
+	game.Observers = append(game.Observers, func(turn int, gameMap hlt.GameMap, moves map[int]hlt.MoveSet, next hlt.GameMap) {
+		if r.NumFrames == 0 {
+			r.AddFrame(gameMap)
+		}
+		r.AddMoves(moves)
+		r.AddFrame(next)
+	})
+	return r
+}
+
+// AddFrame appends the owners and strengths of the map as the next frame
+func (r *Replay) AddFrame(gameMap hlt.GameMap) {
+	frame := make([][][2]int, gameMap.Height)
+	for y := range frame {
+		frame[y] = make([][2]int, gameMap.Width)
+		for x, site := range gameMap.Contents[y] {
+			frame[y][x] = [2]int{site.Owner, site.Strength}
+		}
+	}
+	r.Frames = append(r.Frames, frame)
+	r.NumFrames = len(r.Frames)
+}
+
+// AddMoves appends the moves made by every player on the latest frame. Sites
+// without a move are recorded as STILL.
+func (r *Replay) AddMoves(moves map[int]hlt.MoveSet) {
+	grid := make([][]int, r.Height)
+	for y := range grid {
+		grid[y] = make([]int, r.Width)
+	}
+	for _, ownerMoves := range moves {
+		for _, move := range ownerMoves {
+			if move.Location.Y >= 0 && move.Location.Y < r.Height && move.Location.X >= 0 && move.Location.X < r.Width {
+				grid[move.Location.Y][move.Location.X] = int(move.Direction)
+			}
+		}
+	}
+	r.Moves = append(r.Moves, grid)
+}
+
+// GameMap rebuilds the map as it was at the start of the given turn (frame index)
+func (r *Replay) GameMap(turn int) (hlt.GameMap, error) {
+	if turn < 0 || turn >= len(r.Frames) {
+		return hlt.GameMap{}, fmt.Errorf("replay: turn %d out of range [0, %d)", turn, len(r.Frames))
+	}
+	gameMap := hlt.NewGameMap(r.Width, r.Height)
+	for y := range gameMap.Contents {
+		for x := range gameMap.Contents[y] {
+			gameMap.Contents[y][x] = hlt.Site{
+				Owner:      r.Frames[turn][y][x][0],
+				Strength:   r.Frames[turn][y][x][1],
+				Production: r.Productions[y][x],
+			}
+		}
+	}
+	return gameMap, nil
+}
+
+// MoveSets rebuilds the moves each player made on the given turn, keyed by
+// owner. Only sites owned at the start of the turn have moves.
+func (r *Replay) MoveSets(turn int) (map[int]hlt.MoveSet, error) {
+	if turn < 0 || turn >= len(r.Moves) || turn >= len(r.Frames) {
+		return nil, fmt.Errorf("replay: no moves for turn %d", turn)
+	}
+	moves := make(map[int]hlt.MoveSet)
+	for y := range r.Moves[turn] {
+		for x, direction := range r.Moves[turn][y] {
+			owner := r.Frames[turn][y][x][0]
+			if owner == 0 {
+				continue
+			}
+			moves[owner] = append(moves[owner], hlt.Move{Location: hlt.NewLocation(x, y), Direction: hlt.Direction(direction)})
+		}
+	}
+	return moves, nil
+}
+
+// Write encodes the replay as JSON
+func (r *Replay) Write(writer io.Writer) error {
+	r.NumFrames = len(r.Frames)
+	return json.NewEncoder(writer).Encode(r)
+}
+
+// Save writes the replay to a .hlt file
+func (r *Replay) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	buffered := bufio.NewWriter(f)
+	if err := r.Write(buffered); err != nil {
+		f.Close()
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read decodes a replay, which may be gzip compressed as served by halite.io
+func Read(reader io.Reader) (*Replay, error) {
+	buffered := bufio.NewReader(reader)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		return decode(gz)
+	}
+	return decode(buffered)
+}
+
+// Load reads a replay from a .hlt file
+func Load(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+func decode(reader io.Reader) (*Replay, error) {
+	r := &Replay{}
+	if err := json.NewDecoder(reader).Decode(r); err != nil {
+		return nil, fmt.Errorf("replay: %v", err)
+	}
+	if r.Width <= 0 || r.Height <= 0 || len(r.Productions) != r.Height {
+		return nil, fmt.Errorf("replay: bad dimensions %dx%d", r.Width, r.Height)
+	}
+	for y := range r.Productions {
+		if len(r.Productions[y]) != r.Width {
+			return nil, fmt.Errorf("replay: production row %d has %d sites, expected %d", y, len(r.Productions[y]), r.Width)
+		}
+	}
+	for turn, frame := range r.Frames {
+		if len(frame) != r.Height {
+			return nil, fmt.Errorf("replay: frame %d has %d rows, expected %d", turn, len(frame), r.Height)
+		}
+		for y := range frame {
+			if len(frame[y]) != r.Width {
+				return nil, fmt.Errorf("replay: frame %d row %d has %d sites, expected %d", turn, y, len(frame[y]), r.Width)
+			}
+		}
+	}
+	for turn, moves := range r.Moves {
+		if len(moves) != r.Height {
+			return nil, fmt.Errorf("replay: moves %d has %d rows, expected %d", turn, len(moves), r.Height)
+		}
+		for y := range moves {
+			if len(moves[y]) != r.Width {
+				return nil, fmt.Errorf("replay: moves %d row %d has %d sites, expected %d", turn, y, len(moves[y]), r.Width)
+			}
+		}
+	}
+	r.NumFrames = len(r.Frames)
+	return r, nil
+}
@@ -0,0 +1,122 @@
+package replay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"testing"
+
+	"halite/engine"
+	"halite/hlt"
+)
+
+// stillBot never moves
+type stillBot struct{}
+
+func (stillBot) Update(gameMap hlt.GameMap) {}
+func (stillBot) Moves() hlt.MoveSet         { return hlt.MoveSet{} }
+
+// eastBot moves every piece east once it has some strength
+type eastBot struct {
+	owner   int
+	gameMap hlt.GameMap
+}
+
+func (b *eastBot) Update(gameMap hlt.GameMap) { b.gameMap = gameMap }
+func (b *eastBot) Moves() hlt.MoveSet {
+	moves := hlt.MoveSet{}
+	for y := range b.gameMap.Contents {
+		for x, site := range b.gameMap.Contents[y] {
+			if site.Owner == b.owner && site.Strength > 20 {
+				moves = append(moves, hlt.Move{Location: hlt.NewLocation(x, y), Direction: hlt.EAST})
+			}
+		}
+	}
+	return moves
+}
+
+func recordedGame(t *testing.T) (*engine.Game, *Replay) {
+	game, err := engine.NewGame(engine.Config{Width: 10, Height: 10, Seed: 3, MaxTurns: 20},
+		func(owner int, gameMap hlt.GameMap) engine.Bot { return &eastBot{owner: owner} },
+		func(owner int, gameMap hlt.GameMap) engine.Bot { return stillBot{} },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Record(game, []string{"east", "still"})
+	game.Run()
+	return game, r
+}
+
+func TestRecord(t *testing.T) {
+	game, r := recordedGame(t)
+	if r.NumFrames != game.Turn+1 || len(r.Moves) != game.Turn {
+		fmt.Println("Frames:", r.NumFrames, "Moves:", len(r.Moves), "Turns:", game.Turn)
+		t.Fail()
+	}
+	final, err := r.GameMap(r.NumFrames - 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := range final.Contents {
+		for x := range final.Contents[y] {
+			if final.Contents[y][x] != game.Map.Contents[y][x] {
+				fmt.Println(x, y, final.Contents[y][x], game.Map.Contents[y][x])
+				t.Fail()
+			}
+		}
+	}
+	// replaying the recorded moves reproduces every recorded frame
+	for turn := 0; turn < len(r.Moves); turn++ {
+		gameMap, _ := r.GameMap(turn)
+		moves, err := r.MoveSets(turn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		next := engine.Resolve(gameMap, moves)
+		expected, _ := r.GameMap(turn + 1)
+		for y := range next.Contents {
+			for x := range next.Contents[y] {
+				if next.Contents[y][x] != expected.Contents[y][x] {
+					fmt.Println(turn, x, y, next.Contents[y][x], expected.Contents[y][x])
+					t.Fail()
+				}
+			}
+		}
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	_, r := recordedGame(t)
+	var buffer bytes.Buffer
+	if err := r.Write(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(buffer.Bytes())
+	gz.Close()
+
+	for _, data := range [][]byte{buffer.Bytes(), compressed.Bytes()} {
+		read, err := Read(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read.NumFrames != r.NumFrames || read.NumPlayers != 2 || read.PlayerNames[1] != "still" {
+			fmt.Println(read.NumFrames, read.NumPlayers, read.PlayerNames)
+			t.Fail()
+		}
+		if fmt.Sprint(read.Frames) != fmt.Sprint(r.Frames) || fmt.Sprint(read.Moves) != fmt.Sprint(r.Moves) {
+			fmt.Println("Frames or moves differ after a round trip")
+			t.Fail()
+		}
+	}
+}
+
+func TestReadRejectsBadFrames(t *testing.T) {
+	data := `{"width": 2, "height": 1, "num_players": 1, "productions": [[1, 1]], "frames": [[[[1, 5]]]], "moves": []}`
+	if _, err := Read(bytes.NewReader([]byte(data))); err == nil {
+		fmt.Println("Expected an error for a frame narrower than the map")
+		t.Fail()
+	}
+}
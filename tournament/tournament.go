@@ -0,0 +1,202 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"halite/engine"
+)
+
+// InitialRating every entrant starts a tournament with
+const InitialRating = 1500.0
+
+// EloK is the most an entrant's rating moves in a single game
+const EloK = 32.0
+
+// MapSizes are the square map sizes served by halite.io, used when no size is configured
+var MapSizes = []int{20, 25, 30, 35, 40, 45, 50}
+
+// Entrant is a bot version taking part in a tournament
+type Entrant struct {
+	Name   string
+	NewBot engine.NewBot
+}
+
+// Config describes the games to play. Game i is played on a map generated from
+// Seed + i, so a tournament can be rerun exactly. A zero Width or Height picks
+// one of MapSizes per game.
+type Config struct {
+	Games    int
+	Players  int
+	Seed     int64
+	Width    int
+	Height   int
+	MaxTurns int
+	Parallel int
+}
+
+// Game is the outcome of one game, Seats[i] is the entrant that played as owner i + 1
+type Game struct {
+	Index  int
+	Seed   int64
+	Seats  []int
+	Result engine.Result
+	Err    error
+}
+
+// Standing is an entrant's record over the tournament
+type Standing struct {
+	Name           string
+	Games          int
+	Wins           int
+	Errors         int
+	TotalRank      int
+	TotalTerritory int
+	Rating         float64
+}
+
+// WinRate is the fraction of games finished first
+func (s Standing) WinRate() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return float64(s.Wins) / float64(s.Games)
+}
+
+// AverageRank is the mean finishing position
+func (s Standing) AverageRank() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return float64(s.TotalRank) / float64(s.Games)
+}
+
+// AverageTerritory is the mean number of sites held at the end of a game
+func (s Standing) AverageTerritory() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return float64(s.TotalTerritory) / float64(s.Games)
+}
+
+// Run plays every game and returns the standings, in entrant order, along
+// with each game played. Games run concurrently up to config.Parallel, but
+// ratings are always updated in game order.
+func Run(config Config, entrants []Entrant) ([]Standing, []Game, error) {
+	if config.Players == 0 {
+		config.Players = 2
+	}
+	if config.Parallel <= 0 {
+		config.Parallel = 1
+	}
+	if len(entrants) == 0 {
+		return nil, nil, errors.New("tournament: no entrants")
+	}
+	if config.Players > len(entrants) {
+		return nil, nil, fmt.Errorf("tournament: %d players per game but only %d entrants", config.Players, len(entrants))
+	}
+	games := make([]Game, config.Games)
+	indexes := make(chan int)
+	var wait sync.WaitGroup
+	for worker := 0; worker < config.Parallel; worker++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for i := range indexes {
+				games[i] = play(config, entrants, i)
+			}
+		}()
+	}
+	for i := range games {
+		indexes <- i
+	}
+	close(indexes)
+	wait.Wait()
+
+	standings := make([]Standing, len(entrants))
+	for i, entrant := range entrants {
+		standings[i] = Standing{Name: entrant.Name, Rating: InitialRating}
+	}
+	for _, game := range games {
+		if game.Err != nil {
+			return standings, games, game.Err
+		}
+		Score(standings, game)
+	}
+	return standings, games, nil
+}
+
+// play seats a random selection of entrants, in a random order, on a fresh map
+func play(config Config, entrants []Entrant, index int) Game {
+	seed := config.Seed + int64(index)
+	random := rand.New(rand.NewSource(seed))
+	seats := random.Perm(len(entrants))[:config.Players]
+	width, height := config.Width, config.Height
+	if width <= 0 || height <= 0 {
+		width = MapSizes[random.Intn(len(MapSizes))]
+		height = width
+	}
+	bots := make([]engine.NewBot, len(seats))
+	for owner, entrant := range seats {
+		bots[owner] = entrants[entrant].NewBot
+	}
+	result, err := engine.Play(engine.Config{Width: width, Height: height, Seed: seed, MaxTurns: config.MaxTurns}, bots...)
+	return Game{Index: index, Seed: seed, Seats: seats, Result: result, Err: err}
+}
+
+// Score adds a game to the standings and updates ratings with pairwise Elo: every
+// pair of players in the game is treated as a match won by the better ranked one.
+func Score(standings []Standing, game Game) {
+	deltas := make([]float64, len(game.Seats))
+	pairs := float64(len(game.Seats) - 1)
+	for i, entrant := range game.Seats {
+		player := game.Result.Players[i]
+		standings[entrant].Games++
+		standings[entrant].TotalRank += player.Rank
+		standings[entrant].TotalTerritory += player.Territory
+		if player.Rank == 1 {
+			standings[entrant].Wins++
+		}
+		if player.Err != nil {
+			standings[entrant].Errors++
+		}
+		for j, other := range game.Seats {
+			if i == j {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (standings[other].Rating-standings[entrant].Rating)/400))
+			actual := 0.5
+			if player.Rank < game.Result.Players[j].Rank {
+				actual = 1
+			} else if player.Rank > game.Result.Players[j].Rank {
+				actual = 0
+			}
+			deltas[i] += EloK / pairs * (actual - expected)
+		}
+	}
+	for i, entrant := range game.Seats {
+		standings[entrant].Rating += deltas[i]
+	}
+}
+
+// WriteTable writes the standings, best rated first
+func WriteTable(writer io.Writer, standings []Standing) error {
+	sorted := make([]Standing, len(standings))
+	copy(sorted, standings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Rating > sorted[j].Rating
+	})
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "Bot\tRating\tGames\tWin rate\tAvg rank\tAvg territory\tErrors\t")
+	for _, s := range sorted {
+		fmt.Fprintf(table, "%s\t%.0f\t%d\t%.1f%%\t%.2f\t%.1f\t%d\t\n",
+			s.Name, s.Rating, s.Games, 100*s.WinRate(), s.AverageRank(), s.AverageTerritory(), s.Errors)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,111 @@
+package tournament
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"halite/engine"
+	"halite/hlt"
+)
+
+// expandBot captures any weaker neighbor
+type expandBot struct {
+	owner   int
+	gameMap hlt.GameMap
+}
+
+func (b *expandBot) Update(gameMap hlt.GameMap) { b.gameMap = gameMap }
+func (b *expandBot) Moves() hlt.MoveSet {
+	moves := hlt.MoveSet{}
+	for y := range b.gameMap.Contents {
+		for x, site := range b.gameMap.Contents[y] {
+			if site.Owner != b.owner {
+				continue
+			}
+			location := hlt.NewLocation(x, y)
+			for _, direction := range hlt.CARDINALS {
+				other := b.gameMap.GetSite(location, direction)
+				if other.Owner != b.owner && other.Strength < site.Strength {
+					moves = append(moves, hlt.Move{Location: location, Direction: direction})
+					break
+				}
+			}
+		}
+	}
+	return moves
+}
+
+type stillBot struct{}
+
+func (stillBot) Update(gameMap hlt.GameMap) {}
+func (stillBot) Moves() hlt.MoveSet         { return hlt.MoveSet{} }
+
+func entrants() []Entrant {
+	return []Entrant{
+		{Name: "expand", NewBot: func(owner int, gameMap hlt.GameMap) engine.Bot { return &expandBot{owner: owner} }},
+		{Name: "still", NewBot: func(owner int, gameMap hlt.GameMap) engine.Bot { return stillBot{} }},
+	}
+}
+
+func TestScore(t *testing.T) {
+	standings := []Standing{{Name: "a", Rating: InitialRating}, {Name: "b", Rating: InitialRating}, {Name: "c", Rating: InitialRating}}
+	game := Game{
+		Seats: []int{2, 0, 1},
+		Result: engine.Result{Players: []engine.PlayerResult{
+			{Owner: 1, Rank: 1, Territory: 30},
+			{Owner: 2, Rank: 2, Territory: 10},
+			{Owner: 3, Rank: 3, Territory: 0},
+		}},
+	}
+	Score(standings, game)
+	// equal ratings: winner gains K/2 from each of two pairs, loser loses the same
+	if standings[2].Rating != InitialRating+EloK/2 || standings[0].Rating != InitialRating || standings[1].Rating != InitialRating-EloK/2 {
+		fmt.Println(standings)
+		t.Fail()
+	}
+	if standings[2].Wins != 1 || standings[2].TotalTerritory != 30 || standings[1].AverageRank() != 3 {
+		fmt.Println(standings)
+		t.Fail()
+	}
+}
+
+func TestRun(t *testing.T) {
+	config := Config{Games: 6, Seed: 5, Width: 10, Height: 10, MaxTurns: 40, Parallel: 3}
+	standings, games, err := Run(config, entrants())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 6 || standings[0].Games != 6 || standings[1].Games != 6 {
+		fmt.Println(standings)
+		t.Fail()
+	}
+	if standings[0].WinRate() != 1 || standings[0].Rating <= standings[1].Rating {
+		fmt.Println(standings)
+		t.Fail()
+	}
+	// reruns are identical regardless of parallelism
+	config.Parallel = 1
+	again, _, _ := Run(config, entrants())
+	if fmt.Sprint(again) != fmt.Sprint(standings) {
+		fmt.Println(again)
+		fmt.Println(standings)
+		t.Fail()
+	}
+
+	var buffer bytes.Buffer
+	WriteTable(&buffer, standings)
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "expand") {
+		fmt.Println(buffer.String())
+		t.Fail()
+	}
+}
+
+func TestRunTooManyPlayers(t *testing.T) {
+	if _, _, err := Run(Config{Games: 1, Players: 3}, entrants()); err == nil {
+		fmt.Println("Expected an error seating 3 players from 2 entrants")
+		t.Fail()
+	}
+}
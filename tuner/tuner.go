@@ -0,0 +1,227 @@
+// Package tuner searches bot parameters with a genetic algorithm, rating each
+// generation of parameter sets in a self-play tournament against the starting set.
+package tuner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"halite/engine"
+	"halite/tournament"
+)
+
+// BaseName is the entrant playing the starting parameters in every generation
+const BaseName = "base"
+
+// Dimension is one tunable parameter and the range it is searched over. Name is
+// the parameter's key in the bot's JSON parameter file.
+type Dimension struct {
+	Name    string  `json:"name"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Start   float64 `json:"start"`
+	Integer bool    `json:"integer"`
+}
+
+// Clamp keeps a value inside the dimension's range, rounding integer dimensions
+func (d Dimension) Clamp(value float64) float64 {
+	if d.Integer {
+		value = math.Round(value)
+	}
+	return math.Max(d.Min, math.Min(d.Max, value))
+}
+
+// Params is a parameter set keyed by Dimension name
+type Params map[string]float64
+
+// Start is the parameter set each dimension starts from
+func Start(space []Dimension) Params {
+	params := make(Params, len(space))
+	for _, d := range space {
+		params[d.Name] = d.Clamp(d.Start)
+	}
+	return params
+}
+
+// Save writes the parameters as a JSON object the bot can load
+func (p Params) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// NewBot builds the bot playing with a parameter set
+type NewBot func(name string, params Params) (engine.NewBot, error)
+
+// Config describes the search. Every generation plays Games games between the
+// Population candidates and the starting parameters.
+type Config struct {
+	Generations int
+	Population  int
+	// Fraction of each dimension's range used as the standard deviation of mutations
+	Mutation   float64
+	Tournament tournament.Config
+}
+
+// Entry is a candidate's result in the generation it was played
+type Entry struct {
+	Name       string
+	Generation int
+	Params     Params
+	Standing   tournament.Standing
+	// Advantage is the candidate's rating less the base entrant's rating in the same
+	// generation, which makes ratings from different generations comparable
+	Advantage float64
+}
+
+// Leaderboard is every Entry played, best Advantage first
+type Leaderboard []Entry
+
+// Best is the entry with the highest Advantage
+func (l Leaderboard) Best() (Entry, bool) {
+	if len(l) == 0 {
+		return Entry{}, false
+	}
+	return l[0], true
+}
+
+func (l Leaderboard) sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		return l[i].Advantage > l[j].Advantage
+	})
+}
+
+// Run evolves a population from the starting parameters. After each generation
+// the best half is kept and the rest replaced by mutated crossovers of it.
+// progress, if not nil, sees the leaderboard after every generation.
+func Run(config Config, space []Dimension, newBot NewBot, progress func(generation int, board Leaderboard)) (Leaderboard, error) {
+	if len(space) == 0 {
+		return nil, errors.New("tuner: no dimensions to search")
+	}
+	if config.Population < 2 {
+		return nil, fmt.Errorf("tuner: population of %d, need at least 2", config.Population)
+	}
+	if config.Mutation <= 0 {
+		config.Mutation = 0.1
+	}
+	random := rand.New(rand.NewSource(config.Tournament.Seed))
+	base := Start(space)
+	population := make([]Params, config.Population)
+	for i := range population {
+		population[i] = Mutate(space, base, config.Mutation, random)
+	}
+	board := Leaderboard{}
+	for generation := 0; generation < config.Generations; generation++ {
+		entries, err := play(config, generation, base, population, newBot)
+		if err != nil {
+			return board, err
+		}
+		board = append(board, entries...)
+		board.sort()
+		if progress != nil {
+			progress(generation, board)
+		}
+		// entries are best first, keep the top half as parents
+		parents := make([]Params, 0, len(entries))
+		for _, entry := range entries[:max(1, len(entries)/2)] {
+			parents = append(parents, entry.Params)
+		}
+		population = append(population[:0], parents...)
+		for len(population) < config.Population {
+			a := parents[random.Intn(len(parents))]
+			b := parents[random.Intn(len(parents))]
+			population = append(population, Mutate(space, Crossover(space, a, b, random), config.Mutation, random))
+		}
+	}
+	return board, nil
+}
+
+// play rates one generation against the base parameters, returning its entries best first
+func play(config Config, generation int, base Params, population []Params, newBot NewBot) ([]Entry, error) {
+	entrants := make([]tournament.Entrant, 0, len(population)+1)
+	bot, err := newBot(BaseName, base)
+	if err != nil {
+		return nil, err
+	}
+	entrants = append(entrants, tournament.Entrant{Name: BaseName, NewBot: bot})
+	for i, params := range population {
+		name := fmt.Sprintf("g%d-%d", generation, i)
+		bot, err := newBot(name, params)
+		if err != nil {
+			return nil, err
+		}
+		entrants = append(entrants, tournament.Entrant{Name: name, NewBot: bot})
+	}
+	tournamentConfig := config.Tournament
+	tournamentConfig.Seed += int64(generation * tournamentConfig.Games)
+	standings, _, err := tournament.Run(tournamentConfig, entrants)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, len(population))
+	for i, params := range population {
+		entries[i] = Entry{
+			Name:       entrants[i+1].Name,
+			Generation: generation,
+			Params:     params,
+			Standing:   standings[i+1],
+			Advantage:  standings[i+1].Rating - standings[0].Rating,
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Advantage > entries[j].Advantage
+	})
+	return entries, nil
+}
+
+// Mutate adds gaussian noise of mutation times each dimension's range
+func Mutate(space []Dimension, params Params, mutation float64, random *rand.Rand) Params {
+	mutated := make(Params, len(space))
+	for _, d := range space {
+		mutated[d.Name] = d.Clamp(params[d.Name] + random.NormFloat64()*mutation*(d.Max-d.Min))
+	}
+	return mutated
+}
+
+// Crossover takes each dimension from either parent
+func Crossover(space []Dimension, a Params, b Params, random *rand.Rand) Params {
+	child := make(Params, len(space))
+	for _, d := range space {
+		if random.Intn(2) == 0 {
+			child[d.Name] = a[d.Name]
+		} else {
+			child[d.Name] = b[d.Name]
+		}
+	}
+	return child
+}
+
+// WriteTable writes the leaderboard's top entries with their parameters
+func WriteTable(writer io.Writer, board Leaderboard, space []Dimension, top int) error {
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(table, "Candidate\tAdvantage\tGames\tWin rate\t")
+	for _, d := range space {
+		fmt.Fprintf(table, "%s\t", d.Name)
+	}
+	fmt.Fprintln(table)
+	for i, entry := range board {
+		if i == top {
+			break
+		}
+		fmt.Fprintf(table, "%s\t%+.0f\t%d\t%.1f%%\t", entry.Name, entry.Advantage, entry.Standing.Games, 100*entry.Standing.WinRate())
+		for _, d := range space {
+			fmt.Fprintf(table, "%.3g\t", entry.Params[d.Name])
+		}
+		fmt.Fprintln(table)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,118 @@
+package tuner
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"halite/engine"
+	"halite/hlt"
+	"halite/tournament"
+)
+
+// waitBot captures weaker neighbors once a site has wait times its production
+type waitBot struct {
+	owner   int
+	wait    int
+	gameMap hlt.GameMap
+}
+
+func (b *waitBot) Update(gameMap hlt.GameMap) { b.gameMap = gameMap }
+func (b *waitBot) Moves() hlt.MoveSet {
+	moves := hlt.MoveSet{}
+	for y := range b.gameMap.Contents {
+		for x, site := range b.gameMap.Contents[y] {
+			if site.Owner != b.owner || site.Strength < site.Production*b.wait {
+				continue
+			}
+			location := hlt.NewLocation(x, y)
+			for _, direction := range hlt.CARDINALS {
+				other := b.gameMap.GetSite(location, direction)
+				if other.Owner != b.owner && other.Strength < site.Strength {
+					moves = append(moves, hlt.Move{Location: location, Direction: direction})
+					break
+				}
+			}
+		}
+	}
+	return moves
+}
+
+func newWaitBot(name string, params Params) (engine.NewBot, error) {
+	wait := int(params["wait"])
+	return func(owner int, gameMap hlt.GameMap) engine.Bot {
+		return &waitBot{owner: owner, wait: wait}
+	}, nil
+}
+
+var waitSpace = []Dimension{{Name: "wait", Min: 0, Max: 40, Start: 30, Integer: true}}
+
+func TestDimensionClamp(t *testing.T) {
+	d := Dimension{Name: "size", Min: 3, Max: 7, Integer: true}
+	if d.Clamp(2) != 3 || d.Clamp(9) != 7 || d.Clamp(4.6) != 5 {
+		fmt.Println(d.Clamp(2), d.Clamp(9), d.Clamp(4.6))
+		t.Fail()
+	}
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		if value := Mutate([]Dimension{d}, Params{"size": 5}, 1, random)["size"]; value < 3 || value > 7 || value != float64(int(value)) {
+			fmt.Println("Mutation out of range:", value)
+			t.Fail()
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	config := Config{
+		Generations: 3,
+		Population:  4,
+		Mutation:    0.3,
+		Tournament:  tournament.Config{Games: 10, Seed: 3, Width: 10, Height: 10, MaxTurns: 40, Parallel: 2},
+	}
+	generations := 0
+	board, err := Run(config, waitSpace, newWaitBot, func(generation int, board Leaderboard) {
+		generations++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generations != 3 || len(board) != 12 {
+		fmt.Println("Generations:", generations, "entries:", len(board))
+		t.Fail()
+	}
+	// waiting less than the start expands faster and beats it
+	best, _ := board.Best()
+	if best.Params["wait"] >= waitSpace[0].Start || best.Advantage <= 0 {
+		fmt.Println("Best:", best)
+		t.Fail()
+	}
+	// runs are reproducible from the seed
+	again, _ := Run(config, waitSpace, newWaitBot, nil)
+	if fmt.Sprint(again) != fmt.Sprint(board) {
+		fmt.Println(again)
+		fmt.Println(board)
+		t.Fail()
+	}
+
+	path := filepath.Join(t.TempDir(), "best.json")
+	if err := best.Params.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	WriteTable(&buffer, board, waitSpace, 5)
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 6 || !strings.Contains(lines[1], best.Name) {
+		fmt.Println(buffer.String())
+		t.Fail()
+	}
+}
+
+func TestRunNeedsDimensions(t *testing.T) {
+	if _, err := Run(Config{Generations: 1, Population: 2}, nil, newWaitBot, nil); err == nil {
+		fmt.Println("Expected an error with no dimensions")
+		t.Fail()
+	}
+}
@@ -3,7 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
-	"hlt"
+	"halite/hlt"
 	"os"
 	"sync"
 )
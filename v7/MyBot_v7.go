@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"hlt"
+	"halite/hlt"
 	"os"
 	"sort"
 )
@@ -187,7 +187,7 @@ func log(a ...interface{}) {
 	if err != nil {
 		panic(err)
 	}
-	str := fmt.Sprintln(a)
+	str := fmt.Sprintln(a...)
 	if _, err = f.WriteString(str); err != nil {
 		panic(err)
 	}
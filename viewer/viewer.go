@@ -0,0 +1,254 @@
+// Package viewer steps through a recorded game in the terminal, replaying a
+// bot.Bot on each frame to show its flow fields and the moves it would make.
+package viewer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"halite/bot"
+	"halite/hlt"
+	"halite/render"
+	"halite/replay"
+)
+
+// viewHelp lists the Viewer's commands
+const viewHelp = `enter/n next turn, p previous turn, g <turn> go to turn
+b body flow, r prod flow, t <owner> threat flow of owner, o overlay off
+m toggle moves, ? help, q quit`
+
+// Viewer steps through a recorded game in the terminal as the Bot playing
+// Owner. Each frame shows owners and strengths, optionally an Overlay flow
+// (any name Bot.Flow takes) and the moves the Bot would make.
+type Viewer struct {
+	Replay  *replay.Replay
+	Owner   int
+	Bot     *bot.Bot
+	Turn    int
+	Overlay string
+	Moves   bool
+	// Color draws owners and flow costs with 24-bit ANSI backgrounds,
+	// otherwise each cell is prefixed with its owner
+	Color  bool
+	moves  hlt.MoveSet
+	status string
+}
+
+// NewViewer is a constructor, starting on the first frame
+func NewViewer(r *replay.Replay, owner int) (*Viewer, error) {
+	v := &Viewer{Replay: r, Owner: owner, Moves: true}
+	return v, v.Seek(0)
+}
+
+// Seek moves to a turn, clamped to the recorded frames, and replays the Bot on
+// it. The Bot learns from consecutive frames, so it plays on to the next turn
+// and is rebuilt with NewBotFromReplay on any other, keeping its strategies
+// and Params.
+func (v *Viewer) Seek(turn int) error {
+	turn = max(0, min(turn, v.Replay.NumFrames-1))
+	if v.Bot != nil && turn == v.Turn+1 {
+		gameMap, err := v.Replay.GameMap(turn)
+		if err != nil {
+			return err
+		}
+		v.Bot.Update(gameMap)
+	} else {
+		rebuilt, err := bot.NewBotFromReplay(v.Owner, v.Replay, turn)
+		if err != nil {
+			return err
+		}
+		if v.Bot != nil {
+			rebuilt.Params = v.Bot.Params
+			rebuilt.BorderStrategy, rebuilt.BorderStrategyName = v.Bot.BorderStrategy, v.Bot.BorderStrategyName
+			rebuilt.BodyStrategy, rebuilt.BodyStrategyName = v.Bot.BodyStrategy, v.Bot.BodyStrategyName
+		}
+		v.Bot = rebuilt
+	}
+	v.Turn = turn
+	v.moves = v.Bot.Moves()
+	return nil
+}
+
+// Do runs one command, returning true when the viewer should quit
+func (v *Viewer) Do(command string) (bool, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		fields = []string{"n"}
+	}
+	v.status = ""
+	argument := func() (int, error) {
+		if len(fields) < 2 {
+			return 0, fmt.Errorf("%s needs a number", fields[0])
+		}
+		return strconv.Atoi(fields[1])
+	}
+	switch fields[0] {
+	case "n":
+		return false, v.Seek(v.Turn + 1)
+	case "p":
+		return false, v.Seek(v.Turn - 1)
+	case "g":
+		turn, err := argument()
+		if err != nil {
+			return false, err
+		}
+		return false, v.Seek(turn)
+	case "b":
+		v.toggle("body")
+	case "r":
+		v.toggle("prod")
+	case "t":
+		owner, err := argument()
+		if err != nil {
+			return false, err
+		}
+		v.toggle(fmt.Sprintf("threat:%d", owner))
+	case "o":
+		v.Overlay = ""
+	case "m":
+		v.Moves = !v.Moves
+	case "?", "h":
+		v.status = viewHelp
+	case "q":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown command %q, ? for help", fields[0])
+	}
+	return false, nil
+}
+
+func (v *Viewer) toggle(overlay string) {
+	if v.Overlay == overlay {
+		v.Overlay = ""
+	} else {
+		v.Overlay = overlay
+	}
+}
+
+// Render draws the current frame: a header with the turn, overlay and every
+// player's score, then one 5 column cell per site holding the strength, or the
+// overlay's cost, and a marker. The marker is the Bot's move where it moves,
+// else the overlay's direction.
+func (v *Viewer) Render(writer io.Writer) error {
+	field := bot.NewEmptyFlow()
+	if v.Overlay != "" {
+		overlay, err := v.Bot.Flow(v.Overlay)
+		if err != nil {
+			return err
+		}
+		field = overlay
+	}
+	grid := render.FlowGrid(field, v.Bot.Cells, v.Turn)
+	low, high := grid.CostRange()
+	moves := map[hlt.Location]hlt.Direction{}
+	if v.Moves {
+		for _, move := range v.moves {
+			if move.Direction != hlt.STILL {
+				moves[move.Location] = move.Direction
+			}
+		}
+	}
+
+	var buffer bytes.Buffer
+	overlay := v.Overlay
+	if overlay == "" {
+		overlay = "none"
+	}
+	fmt.Fprintf(&buffer, "turn %d/%d  owner %d  overlay %s  moves %d (%s)\n", v.Turn, v.Replay.NumFrames-1, v.Owner, overlay, len(moves), v.differences())
+	for owner := 1; owner <= v.Replay.NumPlayers; owner++ {
+		name := ""
+		if owner <= len(v.Replay.PlayerNames) {
+			name = v.Replay.PlayerNames[owner-1]
+		}
+		fmt.Fprintf(&buffer, "%s %d %s %s\n", v.swatch(owner), owner, name, bot.ScoreString(bot.ScoreOf(v.Bot.Cells, owner)))
+	}
+	cells := v.Bot.Cells
+	for y := 0; y < cells.Height; y++ {
+		for x := 0; x < cells.Width; x++ {
+			cell := cells.Get(x, y)
+			value := cell.Strength
+			marker := " "
+			if field.Reached(cell.Location) {
+				value = field.Cost(cell.Location)
+				marker = bot.DirectionArrowString(field.Direction(cell.Location))
+			}
+			if direction, ok := moves[cell.Location]; ok {
+				marker = bot.DirectionArrowString(direction)
+			}
+			text := fmt.Sprintf("%3d%s", min(value, 999), marker)
+			if v.Color {
+				c := grid.CellColor(y*cells.Width+x, low, high)
+				fmt.Fprintf(&buffer, "\x1b[48;2;%d;%d;%dm\x1b[97m %s\x1b[0m", c.R, c.G, c.B, text)
+			} else if cell.Owner == 0 {
+				buffer.WriteString("." + text)
+			} else {
+				buffer.WriteString(strconv.Itoa(cell.Owner%10) + text)
+			}
+		}
+		buffer.WriteString("\n")
+	}
+	if v.status != "" {
+		buffer.WriteString(v.status + "\n")
+	}
+	_, err := writer.Write(buffer.Bytes())
+	return err
+}
+
+// swatch shows an owner's color, or a plain # without Color
+func (v *Viewer) swatch(owner int) string {
+	if !v.Color {
+		return "#"
+	}
+	c := render.OwnerColor(owner)
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm  \x1b[0m", c.R, c.G, c.B)
+}
+
+// differences counts the Bot's moves that differ from the ones recorded for Owner
+func (v *Viewer) differences() string {
+	recorded, err := v.Replay.MoveSets(v.Turn)
+	if err != nil {
+		return "no recorded moves"
+	}
+	played := map[hlt.Location]hlt.Direction{}
+	for _, move := range recorded[v.Owner] {
+		played[move.Location] = move.Direction
+	}
+	differ := 0
+	for _, move := range v.moves {
+		if played[move.Location] != move.Direction {
+			differ++
+		}
+	}
+	return fmt.Sprintf("%d differ from the recording", differ)
+}
+
+// Run reads commands a line at a time from reader, redrawing the frame on writer
+// after each, until q or the end of input
+func (v *Viewer) Run(reader io.Reader, writer io.Writer) error {
+	scanner := bufio.NewScanner(reader)
+	for {
+		if v.Color {
+			// clear the screen and draw from the top left
+			fmt.Fprint(writer, "\x1b[H\x1b[2J")
+		}
+		if err := v.Render(writer); err != nil {
+			return err
+		}
+		fmt.Fprint(writer, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(writer)
+			return scanner.Err()
+		}
+		quit, err := v.Do(scanner.Text())
+		if err != nil {
+			v.status = err.Error()
+		}
+		if quit {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package viewer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"halite/bot"
+	"halite/engine"
+	"halite/hlt"
+	"halite/replay"
+)
+
+func TestViewer(t *testing.T) {
+	newBot := func(owner int, gameMap hlt.GameMap) engine.Bot {
+		return bot.NewBot(owner, gameMap)
+	}
+	game, err := engine.NewGame(engine.Config{Width: 10, Height: 8, Seed: 2, MaxTurns: 30}, newBot, newBot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := replay.Record(game, []string{"BrevBot", "BrevBot"})
+	game.Run()
+
+	viewer, err := NewViewer(r, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := viewer.Bot.UseStrategies("projection", bot.DefaultBodyStrategy); err != nil {
+		t.Fatal(err)
+	}
+	// the first frame is not compared with itself
+	if viewer.Bot.Opponents.Observations != 0 {
+		fmt.Println("Observed on the first frame:", viewer.Bot.Opponents.Observations)
+		t.Fail()
+	}
+	var output bytes.Buffer
+	if err := viewer.Run(strings.NewReader("n\n\ng 12\nb\nt 2\nm\nfly\nq\nn\n"), &output); err != nil {
+		t.Fatal(err)
+	}
+	if viewer.Turn != 12 || viewer.Overlay != "threat:2" || viewer.Moves {
+		fmt.Println("Viewer:", viewer.Turn, viewer.Overlay, viewer.Moves)
+		t.Fail()
+	}
+	frames := strings.Split(output.String(), "> ")
+	if len(frames) != 9 || !strings.Contains(frames[1], "turn 1/") || !strings.Contains(frames[3], "turn 12/") || !strings.Contains(frames[7], `unknown command "fly"`) {
+		fmt.Println(output.String())
+		t.Fail()
+	}
+	// a header line per player, then a row of 5 column cells per map row
+	rows := strings.Split(strings.TrimSpace(frames[4]), "\n")
+	if len(rows) != 3+8 || len(rows[3]) != 10*5 || !strings.Contains(rows[0], "overlay body") {
+		fmt.Println(frames[4])
+		t.Fail()
+	}
+
+	// stepping back rebuilds the bot as it would be on that turn
+	if _, err := viewer.Do("p"); err != nil {
+		t.Fatal(err)
+	}
+	rebuilt, err := bot.NewBotFromReplay(1, r, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuilt.UseStrategies("projection", bot.DefaultBodyStrategy)
+	if viewer.Turn != 11 || viewer.Bot.Opponents.Observations != 0 || fmt.Sprint(viewer.moves) != fmt.Sprint(rebuilt.Moves()) {
+		fmt.Println("Stepped back to:", viewer.Turn, viewer.Bot.Opponents.Observations, viewer.moves)
+		t.Fail()
+	}
+	if _, err := viewer.Do("n"); err != nil || viewer.Turn != 12 || viewer.Bot.Opponents.Observations == 0 {
+		fmt.Println("Stepping on should keep observing:", viewer.Turn, err)
+		t.Fail()
+	}
+	if _, err := viewer.Do("g 1000"); err != nil || viewer.Turn != r.NumFrames-1 {
+		fmt.Println("Seeking past the end should stop on the last frame:", viewer.Turn, err)
+		t.Fail()
+	}
+	viewer.Overlay = "nope"
+	if err := viewer.Render(&output); err == nil {
+		fmt.Println("Expected an error for an unknown overlay")
+		t.Fail()
+	}
+	viewer.Overlay = "prod"
+	viewer.Color = true
+	output.Reset()
+	if err := viewer.Render(&output); err != nil || !strings.Contains(output.String(), "\x1b[48;2;") {
+		fmt.Println(output.String(), err)
+		t.Fail()
+	}
+}